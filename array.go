@@ -2,14 +2,15 @@ package threadsafe
 
 import (
 	"reflect"
-	"sync"
 )
 
 // Array represents a thread-safe array.
 // It uses a mutex to ensure that all operations are thread-safe.
 type Array[T any] struct {
 	data []T
-	mu   sync.RWMutex
+	mu   rwMutex
+	esc  escapeGuard
+	id   lockID
 }
 
 // NewArray creates a new thread-safe array with a given size.
@@ -26,6 +27,7 @@ func NewArray[T any](size int) *Array[T] {
 //
 //	value, ok := arr.Get(2)
 func (a *Array[T]) Get(index int) (T, bool) {
+	a.mu.count("Get")
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	if index < 0 || index >= len(a.data) {
@@ -41,6 +43,7 @@ func (a *Array[T]) Get(index int) (T, bool) {
 //
 //	ok := arr.Set(2, 100)
 func (a *Array[T]) Set(index int, value T) bool {
+	a.mu.count("Set")
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if index < 0 || index >= len(a.data) {
@@ -55,6 +58,7 @@ func (a *Array[T]) Set(index int, value T) bool {
 //
 //	length := arr.Length()
 func (a *Array[T]) Length() int {
+	a.mu.count("Length")
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	return len(a.data)
@@ -65,6 +69,7 @@ func (a *Array[T]) Length() int {
 //
 //	values := arr.Values()
 func (a *Array[T]) Values() []T {
+	a.mu.count("Values")
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	dataCopy := make([]T, len(a.data))
@@ -77,6 +82,7 @@ func (a *Array[T]) Values() []T {
 //
 //	arr.Append(10)
 func (a *Array[T]) Append(value T) {
+	a.mu.count("Append")
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.data = append(a.data, value)
@@ -88,6 +94,7 @@ func (a *Array[T]) Append(value T) {
 //
 //	ok := arr.Remove(2)
 func (a *Array[T]) Remove(index int) bool {
+	a.mu.count("Remove")
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if index < 0 || index >= len(a.data) {
@@ -102,6 +109,7 @@ func (a *Array[T]) Remove(index int) bool {
 //
 //	contains := arr.Contains(10)
 func (a *Array[T]) Contains(value T) bool {
+	a.mu.count("Contains")
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	for _, v := range a.data {
@@ -117,6 +125,7 @@ func (a *Array[T]) Contains(value T) bool {
 //
 //	arr.Clear()
 func (a *Array[T]) Clear() {
+	a.mu.count("Clear")
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.data = []T{}
@@ -128,6 +137,7 @@ func (a *Array[T]) Clear() {
 //
 //	ok := arr.Insert(2, 10)
 func (a *Array[T]) Insert(index int, value T) bool {
+	a.mu.count("Insert")
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if index < 0 || index > len(a.data) {
@@ -142,9 +152,57 @@ func (a *Array[T]) Insert(index int, value T) bool {
 //
 //	copyArray := arr.Copy()
 func (a *Array[T]) Copy() *Array[T] {
+	a.mu.count("Copy")
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	dataCopy := make([]T, len(a.data))
 	copy(dataCopy, a.data)
 	return &Array[T]{data: dataCopy}
 }
+
+// WithLock runs fn with exclusive access to the underlying storage.
+// The slice returned by fn becomes the new contents of the array.
+// fn must not retain data or call other methods on the array.
+// Example:
+//
+//	arr.WithLock(func(data []int) []int {
+//		data[0] = data[1] + data[2]
+//		return append(data, data[0])
+//	})
+func (a *Array[T]) WithLock(fn func(data []T) []T) {
+	a.mu.count("WithLock")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !escapeCheckEnabled() {
+		a.data = fn(a.data)
+		return
+	}
+	a.esc.verify("WithLock")
+	lent := lendSlice(a.data)
+	a.data = lendSlice(fn(lent))
+	retireSlice(&a.esc, lent)
+}
+
+// WithRLock runs fn with shared, read-only access to the underlying storage.
+// fn must not modify or retain data, or call methods that modify the array.
+// Example:
+//
+//	var sum int
+//	arr.WithRLock(func(data []int) {
+//		for _, v := range data {
+//			sum += v
+//		}
+//	})
+func (a *Array[T]) WithRLock(fn func(data []T)) {
+	a.mu.count("WithRLock")
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !escapeCheckEnabled() {
+		fn(a.data)
+		return
+	}
+	a.esc.verify("WithRLock")
+	lent := lendSlice(a.data)
+	fn(lent)
+	retireSlice(&a.esc, lent)
+}
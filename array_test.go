@@ -131,3 +131,27 @@ func TestArrayValues(t *testing.T) {
 		assert.Equal(t, expectedValue, v)
 	}
 }
+
+func TestArrayWithLock(t *testing.T) {
+	arr := NewArray[int](3)
+	arr.WithLock(func(data []int) []int {
+		for i := range data {
+			data[i] = i * 10
+		}
+		return data
+	})
+	assert.Equal(t, []int{0, 10, 20}, arr.Values())
+}
+
+func TestArrayWithRLock(t *testing.T) {
+	arr := NewArray[int](2)
+	arr.Set(0, 1)
+	arr.Set(1, 2)
+	var sum int
+	arr.WithRLock(func(data []int) {
+		for _, v := range data {
+			sum += v
+		}
+	})
+	assert.Equal(t, 3, sum)
+}
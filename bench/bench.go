@@ -0,0 +1,370 @@
+// Package bench measures the threadsafe collections against standard library
+// alternatives under configurable workloads. It backs the benchmarks in this
+// directory and the cmd/tsbench runner.
+//
+// Example:
+//
+//	w := bench.Workload{ReadPercent: 90, Keys: 1024, Distribution: bench.Zipf}
+//	for _, impl := range bench.MapImpls {
+//		elapsed := bench.RunMap(impl, w, 8, 1_000_000)
+//		fmt.Println(impl.Name, elapsed)
+//	}
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hayageek/threadsafe"
+)
+
+// Distribution is how keys or indexes are chosen.
+type Distribution int
+
+const (
+	// Uniform picks every key with the same probability.
+	Uniform Distribution = iota
+	// Zipf concentrates accesses on a few hot keys.
+	Zipf
+)
+
+func (d Distribution) String() string {
+	if d == Zipf {
+		return "zipf"
+	}
+	return "uniform"
+}
+
+// Workload describes the mix of operations a benchmark runs.
+type Workload struct {
+	// ReadPercent is the share of operations that read, from 0 to 100.
+	ReadPercent int
+	// Keys is the number of distinct keys or indexes accessed.
+	Keys int
+	// Distribution is how keys are chosen.
+	Distribution Distribution
+}
+
+func (w Workload) String() string {
+	return fmt.Sprintf("read=%d%%/keys=%d/%s", w.ReadPercent, w.Keys, w.Distribution)
+}
+
+// Workloads returns read-mostly, balanced and write-heavy mixes over keys
+// keys, each with uniform and zipf distributions.
+// Example:
+//
+//	for _, w := range bench.Workloads(1024) {
+//		fmt.Println(w)
+//	}
+func Workloads(keys int) []Workload {
+	var ws []Workload
+	for _, read := range []int{90, 50, 10} {
+		for _, d := range []Distribution{Uniform, Zipf} {
+			ws = append(ws, Workload{ReadPercent: read, Keys: keys, Distribution: d})
+		}
+	}
+	return ws
+}
+
+// Goroutines are the default goroutine counts benchmarks run with.
+var Goroutines = []int{1, 4, 16, 64}
+
+// step is a pre-generated operation: a read or a write of key.
+type step struct {
+	read bool
+	key  int
+}
+
+// scriptLen is the number of pre-generated steps each goroutine cycles through.
+const scriptLen = 4096
+
+// script generates the steps of one goroutine, so that random number
+// generation is not part of the measurement.
+func script(w Workload, seed int64) []step {
+	r := rand.New(rand.NewSource(seed))
+	keys := w.Keys
+	if keys < 1 {
+		keys = 1
+	}
+	var zipf *rand.Zipf
+	if w.Distribution == Zipf && keys > 1 {
+		zipf = rand.NewZipf(r, 1.1, 1, uint64(keys-1))
+	}
+	steps := make([]step, scriptLen)
+	for i := range steps {
+		steps[i].read = r.Intn(100) < w.ReadPercent
+		if zipf != nil {
+			steps[i].key = int(zipf.Uint64())
+		} else {
+			steps[i].key = r.Intn(keys)
+		}
+	}
+	return steps
+}
+
+// run splits ops operations between goroutines and returns the time they
+// took. Scripts are generated before the clock starts.
+func run(w Workload, goroutines, ops int, do func(s step)) time.Duration {
+	if goroutines < 1 {
+		goroutines = 1
+	}
+	scripts := make([][]step, goroutines)
+	for g := range scripts {
+		scripts[g] = script(w, int64(g)+1)
+	}
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for g := 0; g < goroutines; g++ {
+		n := ops / goroutines
+		if g < ops%goroutines {
+			n++
+		}
+		wg.Add(1)
+		go func(steps []step, n int) {
+			defer wg.Done()
+			<-start
+			for i := 0; i < n; i++ {
+				do(steps[i%scriptLen])
+			}
+		}(scripts[g], n)
+	}
+	begin := time.Now()
+	close(start)
+	wg.Wait()
+	return time.Since(begin)
+}
+
+// Map is the key-value interface the map benchmarks use.
+type Map interface {
+	Get(key int) (int, bool)
+	Set(key int, value int)
+}
+
+// MapImpl is a named map implementation.
+type MapImpl struct {
+	Name string
+	New  func() Map
+}
+
+// MapImpls are the map implementations compared by default.
+var MapImpls = []MapImpl{
+	{Name: "threadsafe.Map", New: func() Map { return threadsafe.NewMap[int, int]() }},
+	{Name: "threadsafe.COWMap", New: func() Map { return threadsafe.NewCOWMap[int, int]() }},
+	{Name: "threadsafe.SortedMap", New: func() Map { return threadsafe.NewSortedMap[int, int]() }},
+	{Name: "threadsafe.SkipList", New: func() Map { return threadsafe.NewSkipList[int, int]() }},
+	{Name: "sync.Map", New: func() Map { return &syncMap{} }},
+	{Name: "RWMutex+map", New: func() Map { return &rwMutexMap{data: make(map[int]int)} }},
+}
+
+// RunMap fills a new map from impl with w.Keys keys and returns the time
+// goroutines took to run ops operations of w against it.
+// Example:
+//
+//	elapsed := bench.RunMap(bench.MapImpls[0], w, 8, 1_000_000)
+func RunMap(impl MapImpl, w Workload, goroutines, ops int) time.Duration {
+	m := impl.New()
+	for k := 0; k < w.Keys; k++ {
+		m.Set(k, k)
+	}
+	return run(w, goroutines, ops, func(s step) {
+		if s.read {
+			m.Get(s.key)
+		} else {
+			m.Set(s.key, s.key)
+		}
+	})
+}
+
+// syncMap adapts sync.Map to Map.
+type syncMap struct {
+	m sync.Map
+}
+
+func (m *syncMap) Get(key int) (int, bool) {
+	v, ok := m.m.Load(key)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+func (m *syncMap) Set(key int, value int) {
+	m.m.Store(key, value)
+}
+
+// rwMutexMap is a plain map guarded by a sync.RWMutex.
+type rwMutexMap struct {
+	mu   sync.RWMutex
+	data map[int]int
+}
+
+func (m *rwMutexMap) Get(key int) (int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.data[key]
+	return v, ok
+}
+
+func (m *rwMutexMap) Set(key int, value int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+}
+
+// List is the indexed interface the list benchmarks use.
+type List interface {
+	Get(index int) (int, bool)
+	Set(index int, value int) bool
+}
+
+// ListImpl is a named list implementation. New returns a list of size zeros.
+type ListImpl struct {
+	Name string
+	New  func(size int) List
+}
+
+// ListImpls are the list implementations compared by default.
+var ListImpls = []ListImpl{
+	{Name: "threadsafe.Array", New: func(size int) List { return threadsafe.NewArray[int](size) }},
+	{Name: "threadsafe.Slice", New: func(size int) List {
+		s := threadsafe.NewSlice[int]()
+		for i := 0; i < size; i++ {
+			s.Append(0)
+		}
+		return s
+	}},
+	{Name: "threadsafe.COWSlice", New: func(size int) List {
+		s := threadsafe.NewCOWSlice[int]()
+		for i := 0; i < size; i++ {
+			s.Append(0)
+		}
+		return s
+	}},
+	{Name: "RWMutex+slice", New: func(size int) List { return &rwMutexSlice{data: make([]int, size)} }},
+}
+
+// RunList creates a list of w.Keys elements from impl and returns the time
+// goroutines took to run ops indexed reads and writes of w against it.
+// Example:
+//
+//	elapsed := bench.RunList(bench.ListImpls[0], w, 8, 1_000_000)
+func RunList(impl ListImpl, w Workload, goroutines, ops int) time.Duration {
+	l := impl.New(w.Keys)
+	return run(w, goroutines, ops, func(s step) {
+		if s.read {
+			l.Get(s.key)
+		} else {
+			l.Set(s.key, s.key)
+		}
+	})
+}
+
+// rwMutexSlice is a plain slice guarded by a sync.RWMutex.
+type rwMutexSlice struct {
+	mu   sync.RWMutex
+	data []int
+}
+
+func (s *rwMutexSlice) Get(index int) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if index < 0 || index >= len(s.data) {
+		return 0, false
+	}
+	return s.data[index], true
+}
+
+func (s *rwMutexSlice) Set(index int, value int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index < 0 || index >= len(s.data) {
+		return false
+	}
+	s.data[index] = value
+	return true
+}
+
+// Queue is the interface the queue benchmarks use. Take does not block.
+type Queue interface {
+	Put(value int)
+	Take() (int, bool)
+}
+
+// QueueImpl is a named queue implementation.
+type QueueImpl struct {
+	Name string
+	New  func() Queue
+}
+
+// queueCapacity is the buffer size of the channel implementation; the
+// benchmark never holds more elements than there are goroutines.
+const queueCapacity = 1024
+
+// QueueImpls are the queue implementations compared by default.
+var QueueImpls = []QueueImpl{
+	{Name: "threadsafe.Queue", New: func() Queue { return tsQueue{threadsafe.NewQueue()} }},
+	{Name: "threadsafe.Stack", New: func() Queue { return tsStack{threadsafe.NewStack()} }},
+	{Name: "chan", New: func() Queue { return make(chanQueue, queueCapacity) }},
+}
+
+// RunQueue returns the time goroutines took to move ops elements through a
+// new queue from impl, each goroutine putting an element and then taking one.
+// Example:
+//
+//	elapsed := bench.RunQueue(bench.QueueImpls[0], 8, 1_000_000)
+func RunQueue(impl QueueImpl, goroutines, ops int) time.Duration {
+	if goroutines > queueCapacity {
+		goroutines = queueCapacity
+	}
+	q := impl.New()
+	return run(Workload{Keys: 1}, goroutines, ops, func(s step) {
+		q.Put(s.key)
+		q.Take()
+	})
+}
+
+// tsQueue adapts threadsafe.Queue to Queue.
+type tsQueue struct {
+	q *threadsafe.Queue
+}
+
+func (q tsQueue) Put(value int) { q.q.Enqueue(value) }
+
+func (q tsQueue) Take() (int, bool) {
+	v, ok := q.q.Dequeue()
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+// tsStack adapts threadsafe.Stack to Queue.
+type tsStack struct {
+	s *threadsafe.Stack
+}
+
+func (s tsStack) Put(value int) { s.s.Push(value) }
+
+func (s tsStack) Take() (int, bool) {
+	v, ok := s.s.Pop()
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+// chanQueue adapts a buffered channel to Queue.
+type chanQueue chan int
+
+func (c chanQueue) Put(value int) { c <- value }
+
+func (c chanQueue) Take() (int, bool) {
+	select {
+	case v := <-c:
+		return v, true
+	default:
+		return 0, false
+	}
+}
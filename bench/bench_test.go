@@ -0,0 +1,106 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// benchKeys is the number of keys the benchmark workloads access.
+const benchKeys = 1024
+
+// report replaces the measured ns/op with the time spent in the timed section.
+func report(b *testing.B, run func() int64) {
+	b.ReportMetric(float64(run())/float64(b.N), "ns/op")
+}
+
+func BenchmarkMap(b *testing.B) {
+	for _, impl := range MapImpls {
+		for _, w := range Workloads(benchKeys) {
+			for _, g := range Goroutines {
+				b.Run(fmt.Sprintf("%s/%s/g=%d", impl.Name, w, g), func(b *testing.B) {
+					report(b, func() int64 { return int64(RunMap(impl, w, g, b.N)) })
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkList(b *testing.B) {
+	for _, impl := range ListImpls {
+		for _, w := range Workloads(benchKeys) {
+			for _, g := range Goroutines {
+				b.Run(fmt.Sprintf("%s/%s/g=%d", impl.Name, w, g), func(b *testing.B) {
+					report(b, func() int64 { return int64(RunList(impl, w, g, b.N)) })
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+	for _, impl := range QueueImpls {
+		for _, g := range Goroutines {
+			b.Run(fmt.Sprintf("%s/g=%d", impl.Name, g), func(b *testing.B) {
+				report(b, func() int64 { return int64(RunQueue(impl, g, b.N)) })
+			})
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	for _, w := range Workloads(16) {
+		reads := 0
+		for _, s := range script(w, 1) {
+			assert.True(t, s.key >= 0 && s.key < 16, "key %d out of range", s.key)
+			if s.read {
+				reads++
+			}
+		}
+		share := reads * 100 / scriptLen
+		assert.InDelta(t, w.ReadPercent, share, 5, "%s", w)
+	}
+}
+
+func TestRunners(t *testing.T) {
+	w := Workload{ReadPercent: 50, Keys: 8, Distribution: Zipf}
+	for _, impl := range MapImpls {
+		assert.Greater(t, int64(RunMap(impl, w, 4, 1000)), int64(0), impl.Name)
+	}
+	for _, impl := range ListImpls {
+		assert.Greater(t, int64(RunList(impl, w, 4, 1000)), int64(0), impl.Name)
+	}
+	for _, impl := range QueueImpls {
+		assert.Greater(t, int64(RunQueue(impl, 4, 1000)), int64(0), impl.Name)
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	for _, impl := range MapImpls {
+		m := impl.New()
+		m.Set(1, 10)
+		v, ok := m.Get(1)
+		assert.True(t, ok, impl.Name)
+		assert.Equal(t, 10, v, impl.Name)
+		_, ok = m.Get(2)
+		assert.False(t, ok, impl.Name)
+	}
+	for _, impl := range ListImpls {
+		l := impl.New(2)
+		assert.True(t, l.Set(1, 10), impl.Name)
+		assert.False(t, l.Set(2, 10), impl.Name)
+		v, ok := l.Get(1)
+		assert.True(t, ok, impl.Name)
+		assert.Equal(t, 10, v, impl.Name)
+	}
+	for _, impl := range QueueImpls {
+		q := impl.New()
+		_, ok := q.Take()
+		assert.False(t, ok, impl.Name)
+		q.Put(1)
+		v, ok := q.Take()
+		assert.True(t, ok, impl.Name)
+		assert.Equal(t, 1, v, impl.Name)
+	}
+}
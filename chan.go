@@ -0,0 +1,148 @@
+package threadsafe
+
+import (
+	"context"
+	"sync"
+)
+
+// Chan returns a channel that streams elements dequeued from the queue,
+// waiting for new elements while the queue is empty. Elements added by any
+// means, including Atomically and decoding, wake the channel. The channel is
+// closed when ctx is done; an element dequeued but not yet received at that
+// point is put back at the front of the queue and reported to OnEnqueue hooks.
+// Example:
+//
+//	for value := range q.Chan(ctx) {
+//		fmt.Println(value)
+//	}
+func (q *Queue) Chan(ctx context.Context) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for {
+			// Wait on the channel obtained before Dequeue, so that an element
+			// added in between is not missed.
+			added := q.added.wait()
+			value, ok := q.Dequeue()
+			if !ok {
+				select {
+				case <-added:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- value:
+			case <-ctx.Done():
+				q.pushFront(value)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// pushFront puts value back at the front of the queue.
+func (q *Queue) pushFront(value interface{}) {
+	var events []queueEvent
+	q.mu.Lock()
+	defer func() { q.unlockAndNotify(events) }()
+	q.front = append(q.front, value)
+	q.added.broadcast()
+	if q.hooks.active() {
+		events = []queueEvent{{enqueued: true, value: value}}
+	}
+}
+
+// waiters lets goroutines wait for elements to be added to a collection.
+type waiters struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// wait returns a channel that is closed the next time broadcast is called.
+func (w *waiters) wait() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ch == nil {
+		w.ch = make(chan struct{})
+	}
+	return w.ch
+}
+
+// broadcast wakes every goroutine waiting on a channel returned by wait.
+func (w *waiters) broadcast() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ch != nil {
+		close(w.ch)
+		w.ch = nil
+	}
+}
+
+// FromChan returns a new queue that receives every value sent on ch.
+// Values are enqueued in the order they are received, until ch is closed.
+// Example:
+//
+//	q := threadsafe.FromChan(events)
+func FromChan[T any](ch <-chan T) *Queue {
+	q := NewQueue()
+	go func() {
+		for value := range ch {
+			q.Enqueue(value)
+		}
+	}()
+	return q
+}
+
+// Chan returns a channel that streams elements popped from the stack,
+// waiting for new elements while the stack is empty. Elements added by any
+// means, including Atomically and decoding, wake the channel. The channel is closed
+// when ctx is done; an element popped but not yet received at that point
+// is pushed back onto the stack.
+// Example:
+//
+//	for value := range s.Chan(ctx) {
+//		fmt.Println(value)
+//	}
+func (s *Stack) Chan(ctx context.Context) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for {
+			added := s.added.wait()
+			value, ok := s.Pop()
+			if !ok {
+				select {
+				case <-added:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- value:
+			case <-ctx.Done():
+				s.Push(value)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// StackFromChan returns a new stack onto which every value sent on ch is pushed,
+// until ch is closed.
+// Example:
+//
+//	s := threadsafe.StackFromChan(undo)
+func StackFromChan[T any](ch <-chan T) *Stack {
+	s := NewStack()
+	go func() {
+		for value := range ch {
+			s.Push(value)
+		}
+	}()
+	return s
+}
@@ -0,0 +1,199 @@
+package threadsafe
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveValue(t *testing.T, ch <-chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case value := <-ch:
+		return value
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for value")
+		return nil
+	}
+}
+
+func TestQueueChan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := NewQueue()
+	queue.Enqueue(1)
+	ch := queue.Chan(ctx)
+	assert.Equal(t, 1, receiveValue(t, ch))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		queue.Enqueue(2)
+	}()
+	assert.Equal(t, 2, receiveValue(t, ch))
+}
+
+func TestQueueChanCancelKeepsPendingValue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := NewQueue()
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	ch := queue.Chan(ctx)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.Eventually(t, func() bool { return queue.Len() == 2 }, time.Second, time.Millisecond)
+	for range ch {
+		t.Fatal("unexpected value after cancel")
+	}
+	assert.Equal(t, []interface{}{1, 2}, queue.Values())
+}
+
+func TestFromChan(t *testing.T) {
+	ch := make(chan string)
+	queue := FromChan(ch)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+	assert.Eventually(t, func() bool { return queue.Len() == 2 }, time.Second, time.Millisecond)
+	value, _ := queue.Dequeue()
+	assert.Equal(t, "a", value)
+}
+
+func TestStackChan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stack := NewStack()
+	stack.Push(1)
+	stack.Push(2)
+	ch := stack.Chan(ctx)
+	assert.Equal(t, 2, receiveValue(t, ch))
+	assert.Equal(t, 1, receiveValue(t, ch))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		stack.Push(3)
+	}()
+	assert.Equal(t, 3, receiveValue(t, ch))
+}
+
+func TestStackChanCancelKeepsPendingValue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stack := NewStack()
+	stack.Push(1)
+	stack.Push(2)
+	ch := stack.Chan(ctx)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.Eventually(t, func() bool { return stack.Len() == 2 }, time.Second, time.Millisecond)
+	for range ch {
+		t.Fatal("unexpected value after cancel")
+	}
+	assert.Equal(t, []interface{}{2, 1}, stack.Values())
+}
+
+func TestStackFromChan(t *testing.T) {
+	ch := make(chan int)
+	stack := StackFromChan(ch)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	assert.Eventually(t, func() bool { return stack.Len() == 2 }, time.Second, time.Millisecond)
+	value, _ := stack.Pop()
+	assert.Equal(t, 2, value)
+}
+
+func TestQueueChanSelect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := FromChan(make(chan int))
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	ch := queue.Chan(ctx)
+	queue.Enqueue("job")
+	for {
+		select {
+		case value := <-ch:
+			assert.Equal(t, "job", value)
+			return
+		case <-ticker.C:
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestQueueChanWakesOnEveryMutation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := NewQueue()
+	ch := queue.Chan(ctx)
+
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, Atomically(func(tx *Txn) error {
+		TxnQueue(tx, queue).Enqueue("txn")
+		return nil
+	}, queue))
+	assert.Equal(t, "txn", receiveValue(t, ch))
+
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, queue.UnmarshalJSON([]byte(`["json"]`)))
+	assert.Equal(t, "json", receiveValue(t, ch))
+
+	other := NewQueue()
+	other.Enqueue("gob")
+	b, err := other.MarshalBinary()
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, queue.UnmarshalBinary(b))
+	assert.Equal(t, "gob", receiveValue(t, ch))
+}
+
+func TestStackChanWakesOnAtomically(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stack := NewStack()
+	ch := stack.Chan(ctx)
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, Atomically(func(tx *Txn) error {
+		TxnStack(tx, stack).Push("txn")
+		return nil
+	}, stack))
+	assert.Equal(t, "txn", receiveValue(t, ch))
+}
+
+func TestQueueChanCancelReportsPutBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := NewQueue()
+	var mu sync.Mutex
+	var enqueued, dequeued []interface{}
+	queue.OnEnqueue(func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		enqueued = append(enqueued, value)
+	})
+	queue.OnDequeue(func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		dequeued = append(dequeued, value)
+	})
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	ch := queue.Chan(ctx)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	for range ch {
+	}
+	assert.Eventually(t, func() bool { return queue.Len() == 2 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(enqueued) == 3
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []interface{}{1, 2, 1}, enqueued)
+	assert.Equal(t, []interface{}{1}, dequeued)
+	assert.Equal(t, []interface{}{1, 2}, queue.Values())
+}
@@ -0,0 +1,140 @@
+// Command tsbench compares the threadsafe collections with standard library
+// alternatives and prints the results as tables of nanoseconds per operation.
+//
+// Usage:
+//
+//	tsbench [-suite map,list,queue] [-ops 1000000] [-goroutines 1,4,16,64] [-keys 1024] [-procs N]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hayageek/threadsafe/bench"
+)
+
+func main() {
+	suites := flag.String("suite", "map,list,queue", "comma-separated suites to run")
+	ops := flag.Int("ops", 1000000, "operations per measurement")
+	goroutines := flag.String("goroutines", "1,4,16,64", "comma-separated goroutine counts")
+	keys := flag.Int("keys", 1024, "number of distinct keys or indexes")
+	procs := flag.Int("procs", 0, "GOMAXPROCS to use; 0 keeps the default")
+	flag.Parse()
+
+	counts, err := parseInts(*goroutines)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tsbench: -goroutines:", err)
+		os.Exit(2)
+	}
+	if *procs > 0 {
+		runtime.GOMAXPROCS(*procs)
+	}
+	fmt.Printf("GOMAXPROCS=%d ops=%d keys=%d (ns/op, lower is better)\n", runtime.GOMAXPROCS(0), *ops, *keys)
+
+	for _, suite := range strings.Split(*suites, ",") {
+		switch strings.TrimSpace(suite) {
+		case "map":
+			for _, w := range bench.Workloads(*keys) {
+				t := newTable("map "+w.String(), counts)
+				for _, impl := range bench.MapImpls {
+					t.row(impl.Name, func(g int) time.Duration { return bench.RunMap(impl, w, g, *ops) })
+				}
+				t.print(*ops)
+			}
+		case "list":
+			for _, w := range bench.Workloads(*keys) {
+				t := newTable("list "+w.String(), counts)
+				for _, impl := range bench.ListImpls {
+					t.row(impl.Name, func(g int) time.Duration { return bench.RunList(impl, w, g, *ops) })
+				}
+				t.print(*ops)
+			}
+		case "queue":
+			t := newTable("queue put+take", counts)
+			for _, impl := range bench.QueueImpls {
+				t.row(impl.Name, func(g int) time.Duration { return bench.RunQueue(impl, g, *ops) })
+			}
+			t.print(*ops)
+		default:
+			fmt.Fprintf(os.Stderr, "tsbench: unknown suite %q\n", suite)
+			os.Exit(2)
+		}
+	}
+}
+
+// table collects the timings of one suite and workload.
+type table struct {
+	title  string
+	counts []int
+	names  []string
+	rows   [][]time.Duration
+}
+
+func newTable(title string, counts []int) *table {
+	return &table{title: title, counts: counts}
+}
+
+// row measures one implementation at every goroutine count.
+func (t *table) row(name string, measure func(goroutines int) time.Duration) {
+	r := make([]time.Duration, len(t.counts))
+	for i, g := range t.counts {
+		r[i] = measure(g)
+	}
+	t.names = append(t.names, name)
+	t.rows = append(t.rows, r)
+}
+
+// print writes the table, marking the fastest implementation in each column.
+func (t *table) print(ops int) {
+	fmt.Printf("\n%s\n", t.title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "\t")
+	for _, g := range t.counts {
+		fmt.Fprintf(w, "g=%d\t", g)
+	}
+	fmt.Fprintln(w)
+	for i, name := range t.names {
+		fmt.Fprintf(w, "%s\t", name)
+		for j, d := range t.rows[i] {
+			mark := " "
+			if t.fastest(j) == i {
+				mark = "*"
+			}
+			fmt.Fprintf(w, "%.1f%s\t", float64(d.Nanoseconds())/float64(ops), mark)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+// fastest returns the row with the lowest timing in column col.
+func (t *table) fastest(col int) int {
+	best := 0
+	for i := range t.rows {
+		if t.rows[i][col] < t.rows[best][col] {
+			best = i
+		}
+	}
+	return best
+}
+
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("count must be positive: %d", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -0,0 +1,220 @@
+package threadsafe
+
+import (
+	"sync/atomic"
+)
+
+// COWMap represents a thread-safe copy-on-write map.
+// Reads are lock-free and work on an immutable snapshot; every write copies
+// the map under a mutex and publishes the new snapshot atomically.
+// It suits read-mostly data such as configuration or routing tables.
+type COWMap[K comparable, V any] struct {
+	data atomic.Pointer[map[K]V]
+	mu   mutex
+}
+
+// NewCOWMap creates a new thread-safe copy-on-write map.
+// Example:
+//
+//	m := threadsafe.NewCOWMap[string, int]()
+func NewCOWMap[K comparable, V any]() *COWMap[K, V] {
+	m := &COWMap[K, V]{}
+	data := make(map[K]V)
+	m.data.Store(&data)
+	return m
+}
+
+// snapshot returns the current immutable map. It must not be modified.
+func (m *COWMap[K, V]) snapshot() map[K]V {
+	if p := m.data.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// update copies the current map, applies fn to the copy and publishes it.
+func (m *COWMap[K, V]) update(fn func(data map[K]V)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data := m.clone(1)
+	fn(data)
+	m.data.Store(&data)
+}
+
+// clone returns a copy of the current map with room for extra more keys.
+func (m *COWMap[K, V]) clone(extra int) map[K]V {
+	old := m.snapshot()
+	data := make(map[K]V, len(old)+extra)
+	for key, value := range old {
+		data[key] = value
+	}
+	return data
+}
+
+// Get retrieves the value associated with the key.
+// It returns the value and a boolean indicating whether the key was found.
+// Example:
+//
+//	value, ok := m.Get("key")
+func (m *COWMap[K, V]) Get(key K) (V, bool) {
+	m.mu.count("Get")
+	value, exists := m.snapshot()[key]
+	return value, exists
+}
+
+// Set sets the value for the given key.
+// Example:
+//
+//	m.Set("key", 100)
+func (m *COWMap[K, V]) Set(key K, value V) {
+	m.mu.count("Set")
+	m.update(func(data map[K]V) {
+		data[key] = value
+	})
+}
+
+// Delete removes the value associated with the key.
+// Example:
+//
+//	m.Delete("key")
+func (m *COWMap[K, V]) Delete(key K) {
+	m.mu.count("Delete")
+	m.update(func(data map[K]V) {
+		delete(data, key)
+	})
+}
+
+// Length returns the number of key-value pairs in the map.
+// Example:
+//
+//	length := m.Length()
+func (m *COWMap[K, V]) Length() int {
+	m.mu.count("Length")
+	return len(m.snapshot())
+}
+
+// Keys returns a slice of all keys present in the map.
+// Example:
+//
+//	keys := m.Keys()
+func (m *COWMap[K, V]) Keys() []K {
+	m.mu.count("Keys")
+	data := m.snapshot()
+	keys := make([]K, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Values returns a slice of all values present in the map.
+// Example:
+//
+//	values := m.Values()
+func (m *COWMap[K, V]) Values() []V {
+	m.mu.count("Values")
+	data := m.snapshot()
+	values := make([]V, 0, len(data))
+	for _, value := range data {
+		values = append(values, value)
+	}
+	return values
+}
+
+// Contains checks if the map contains the specified key.
+// Example:
+//
+//	contains := m.Contains("key")
+func (m *COWMap[K, V]) Contains(key K) bool {
+	m.mu.count("Contains")
+	_, exists := m.snapshot()[key]
+	return exists
+}
+
+// Clear removes all key-value pairs from the map.
+// Example:
+//
+//	m.Clear()
+func (m *COWMap[K, V]) Clear() {
+	m.mu.count("Clear")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data := make(map[K]V)
+	m.data.Store(&data)
+}
+
+// Copy returns a new thread-safe copy-on-write map that is a copy of the current map.
+// Example:
+//
+//	copyMap := m.Copy()
+func (m *COWMap[K, V]) Copy() *COWMap[K, V] {
+	m.mu.count("Copy")
+	data := m.clone(0)
+	c := &COWMap[K, V]{}
+	c.data.Store(&data)
+	return c
+}
+
+// WithLock runs fn with exclusive access to a copy of the map, which replaces
+// the map once fn returns. Concurrent readers keep seeing the previous
+// snapshot until then. fn must not retain data or call other methods on the map.
+// Example:
+//
+//	m.WithLock(func(data map[string]int) {
+//		data["total"] = data["a"] + data["b"]
+//		delete(data, "a")
+//	})
+func (m *COWMap[K, V]) WithLock(fn func(data map[K]V)) {
+	m.mu.count("WithLock")
+	m.update(fn)
+}
+
+// WithRLock runs fn with read-only access to the current snapshot of the map.
+// It takes no lock, since snapshots are never modified; fn must not modify
+// or retain data.
+// Example:
+//
+//	var sum int
+//	m.WithRLock(func(data map[string]int) {
+//		sum = data["a"] + data["b"]
+//	})
+func (m *COWMap[K, V]) WithRLock(fn func(data map[K]V)) {
+	m.mu.count("WithRLock")
+	fn(m.snapshot())
+}
+
+// Tx runs fn as a transaction while holding the map's write lock.
+// If fn returns nil, all changes made through tx are published as a single
+// new snapshot. If fn returns an error or panics, the map is left unchanged.
+// Tx returns the error from fn.
+// Example:
+//
+//	err := m.Tx(func(tx *threadsafe.MapTx[string, int64]) error {
+//		from, _ := tx.Get("alice")
+//		if from < 10 {
+//			return errors.New("insufficient funds")
+//		}
+//		to, _ := tx.Get("bob")
+//		tx.Set("alice", from-10)
+//		tx.Set("bob", to+10)
+//		return nil
+//	})
+func (m *COWMap[K, V]) Tx(fn func(tx *MapTx[K, V]) error) error {
+	m.mu.count("Tx")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// The transaction reads the current snapshot and buffers its writes, so
+	// the map is only copied if it commits changes.
+	tx := &MapTx[K, V]{m: &Map[K, V]{data: m.snapshot()}, changes: make(map[K]txChange[V])}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if len(tx.changes) == 0 {
+		return nil
+	}
+	data := m.clone(len(tx.changes))
+	tx.m.data = data
+	tx.commit()
+	m.data.Store(&data)
+	return nil
+}
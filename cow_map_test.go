@@ -0,0 +1,134 @@
+package threadsafe
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCOWMap(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	assert.Equal(t, 0, m.Length())
+}
+
+func TestCOWMapSetGet(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("key1", 42)
+	value, ok := m.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+}
+
+func TestCOWMapZeroValue(t *testing.T) {
+	var m COWMap[string, int]
+	_, ok := m.Get("key1")
+	assert.False(t, ok)
+	m.Set("key1", 42)
+	assert.Equal(t, 1, m.Length())
+}
+
+func TestCOWMapDelete(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("key1", 42)
+	m.Delete("key1")
+	value, ok := m.Get("key1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, value)
+}
+
+func TestCOWMapKeysValues(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("key1", 42)
+	m.Set("key2", 43)
+	assert.ElementsMatch(t, []string{"key1", "key2"}, m.Keys())
+	assert.ElementsMatch(t, []int{42, 43}, m.Values())
+	assert.True(t, m.Contains("key1"))
+	assert.False(t, m.Contains("nonexistent"))
+}
+
+func TestCOWMapClear(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("key1", 42)
+	m.Clear()
+	assert.Equal(t, 0, m.Length())
+}
+
+func TestCOWMapCopy(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("key1", 42)
+	copyMap := m.Copy()
+	m.Set("key1", 7)
+	value, _ := copyMap.Get("key1")
+	assert.Equal(t, 42, value)
+}
+
+func TestCOWMapConcurrentReadWrite(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Set(strconv.Itoa(i*100+j), j)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				m.Get("1")
+				m.Length()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 400, m.Length())
+}
+
+func TestCOWMapWithLock(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.WithLock(func(data map[string]int) {
+		data["total"] = data["a"] + data["b"]
+		delete(data, "a")
+	})
+	total, _ := m.Get("total")
+	assert.Equal(t, 3, total)
+	assert.False(t, m.Contains("a"))
+
+	var sum int
+	m.WithRLock(func(data map[string]int) {
+		sum = data["b"] + data["total"]
+	})
+	assert.Equal(t, 5, sum)
+}
+
+func TestCOWMapTx(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("alice", 20)
+	before := m.snapshot()
+
+	err := m.Tx(func(tx *MapTx[string, int]) error {
+		from, _ := tx.Get("alice")
+		tx.Set("alice", from-10)
+		tx.Set("bob", 10)
+		return nil
+	})
+	assert.NoError(t, err)
+	alice, _ := m.Get("alice")
+	bob, _ := m.Get("bob")
+	assert.Equal(t, 10, alice)
+	assert.Equal(t, 10, bob)
+	assert.Equal(t, map[string]int{"alice": 20}, before)
+
+	err = m.Tx(func(tx *MapTx[string, int]) error {
+		tx.Delete("alice")
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+	assert.True(t, m.Contains("alice"))
+}
@@ -0,0 +1,202 @@
+package threadsafe
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// COWSlice represents a thread-safe copy-on-write slice.
+// Reads are lock-free and work on an immutable snapshot; every write copies
+// the slice under a mutex and publishes the new snapshot atomically.
+// It suits data that is iterated often but modified rarely, such as listener lists.
+type COWSlice[T any] struct {
+	data atomic.Pointer[[]T]
+	mu   mutex
+}
+
+// NewCOWSlice creates a new thread-safe copy-on-write slice.
+// Example:
+//
+//	slice := threadsafe.NewCOWSlice[int]()
+func NewCOWSlice[T any]() *COWSlice[T] {
+	s := &COWSlice[T]{}
+	data := []T{}
+	s.data.Store(&data)
+	return s
+}
+
+// Snapshot returns the current contents without copying.
+// The returned slice is shared and must not be modified; later writes to
+// the COWSlice do not affect it.
+// Example:
+//
+//	for _, listener := range listeners.Snapshot() {
+//		listener(event)
+//	}
+func (s *COWSlice[T]) Snapshot() []T {
+	s.mu.count("Snapshot")
+	return s.load()
+}
+
+// load returns the current immutable slice. It must not be modified.
+func (s *COWSlice[T]) load() []T {
+	if p := s.data.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// store publishes data as the new snapshot. The snapshot's capacity is
+// clipped to its length, so that appending to a Snapshot allocates instead of
+// writing into spare capacity shared with other readers.
+func (s *COWSlice[T]) store(data []T) {
+	data = data[:len(data):len(data)]
+	s.data.Store(&data)
+}
+
+// update copies the current slice, applies fn to the copy and publishes the result.
+// fn returns the new contents and whether anything changed.
+func (s *COWSlice[T]) update(fn func(data []T) ([]T, bool)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.load()
+	data := make([]T, len(old), len(old)+1)
+	copy(data, old)
+	data, ok := fn(data)
+	if ok {
+		s.store(data)
+	}
+	return ok
+}
+
+// Append appends a value to the slice.
+// Example:
+//
+//	slice.Append(10)
+func (s *COWSlice[T]) Append(value T) {
+	s.mu.count("Append")
+	s.update(func(data []T) ([]T, bool) {
+		return append(data, value), true
+	})
+}
+
+// Get retrieves the value at the given index.
+// It returns the value and a boolean indicating whether the index was valid.
+// Example:
+//
+//	value, ok := slice.Get(2)
+func (s *COWSlice[T]) Get(index int) (T, bool) {
+	s.mu.count("Get")
+	data := s.load()
+	if index < 0 || index >= len(data) {
+		var zero T
+		return zero, false
+	}
+	return data[index], true
+}
+
+// Set sets the value at the given index.
+// It returns a boolean indicating whether the operation was successful.
+// Example:
+//
+//	ok := slice.Set(2, 100)
+func (s *COWSlice[T]) Set(index int, value T) bool {
+	s.mu.count("Set")
+	return s.update(func(data []T) ([]T, bool) {
+		if index < 0 || index >= len(data) {
+			return data, false
+		}
+		data[index] = value
+		return data, true
+	})
+}
+
+// Length returns the length of the slice.
+// Example:
+//
+//	length := slice.Length()
+func (s *COWSlice[T]) Length() int {
+	s.mu.count("Length")
+	return len(s.load())
+}
+
+// Values returns a copy of the slice's data as a regular slice.
+// Example:
+//
+//	values := slice.Values()
+func (s *COWSlice[T]) Values() []T {
+	s.mu.count("Values")
+	data := s.load()
+	dataCopy := make([]T, len(data))
+	copy(dataCopy, data)
+	return dataCopy
+}
+
+// Remove removes the element at the given index.
+// It returns a boolean indicating whether the operation was successful.
+// Example:
+//
+//	ok := slice.Remove(2)
+func (s *COWSlice[T]) Remove(index int) bool {
+	s.mu.count("Remove")
+	return s.update(func(data []T) ([]T, bool) {
+		if index < 0 || index >= len(data) {
+			return data, false
+		}
+		return append(data[:index], data[index+1:]...), true
+	})
+}
+
+// Contains checks if the slice contains the specified value.
+// Example:
+//
+//	contains := slice.Contains(10)
+func (s *COWSlice[T]) Contains(value T) bool {
+	s.mu.count("Contains")
+	for _, v := range s.load() {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all elements from the slice.
+// Example:
+//
+//	slice.Clear()
+func (s *COWSlice[T]) Clear() {
+	s.mu.count("Clear")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store([]T{})
+}
+
+// Insert inserts a value at the specified index.
+// It returns a boolean indicating whether the operation was successful.
+// Example:
+//
+//	ok := slice.Insert(2, 10)
+func (s *COWSlice[T]) Insert(index int, value T) bool {
+	s.mu.count("Insert")
+	return s.update(func(data []T) ([]T, bool) {
+		if index < 0 || index > len(data) {
+			return data, false
+		}
+		return append(data[:index], append([]T{value}, data[index:]...)...), true
+	})
+}
+
+// Copy returns a new thread-safe copy-on-write slice that is a copy of the current slice.
+// Example:
+//
+//	copySlice := slice.Copy()
+func (s *COWSlice[T]) Copy() *COWSlice[T] {
+	s.mu.count("Copy")
+	data := s.load()
+	dataCopy := make([]T, len(data))
+	copy(dataCopy, data)
+	c := &COWSlice[T]{}
+	c.data.Store(&dataCopy)
+	return c
+}
@@ -0,0 +1,100 @@
+package threadsafe
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCOWSlice(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	assert.Equal(t, 0, slice.Length())
+}
+
+func TestCOWSliceAppendGetSet(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	slice.Append(1)
+	slice.Append(2)
+	ok := slice.Set(1, 42)
+	assert.True(t, ok)
+	value, ok := slice.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+	assert.False(t, slice.Set(10, 1))
+	_, ok = slice.Get(10)
+	assert.False(t, ok)
+}
+
+func TestCOWSliceSnapshotIsStable(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	slice.Append(1)
+	slice.Append(2)
+	snapshot := slice.Snapshot()
+	slice.Set(0, 42)
+	slice.Append(3)
+	slice.Remove(1)
+	assert.Equal(t, []int{1, 2}, snapshot)
+	assert.Equal(t, []int{42, 3}, slice.Values())
+}
+
+func TestCOWSliceSnapshotAppendDoesNotShare(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	slice.Append(1)
+	slice.Append(2)
+	slice.Set(0, 10)
+	slice.Remove(1)
+	slice.Append(3)
+
+	snapshot := slice.Snapshot()
+	assert.Equal(t, len(snapshot), cap(snapshot))
+	a := append(slice.Snapshot(), 100)
+	b := append(slice.Snapshot(), 200)
+	assert.Equal(t, []int{10, 3, 100}, a)
+	assert.Equal(t, []int{10, 3, 200}, b)
+	assert.Equal(t, []int{10, 3}, slice.Values())
+}
+
+func TestCOWSliceRemoveInsert(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	slice.Append(1)
+	slice.Append(2)
+	slice.Append(3)
+	assert.True(t, slice.Remove(1))
+	assert.False(t, slice.Remove(10))
+	assert.True(t, slice.Insert(2, 4))
+	assert.False(t, slice.Insert(10, 4))
+	assert.Equal(t, []int{1, 3, 4}, slice.Values())
+}
+
+func TestCOWSliceContainsClearCopy(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	slice.Append(1)
+	assert.True(t, slice.Contains(1))
+	assert.False(t, slice.Contains(42))
+	copySlice := slice.Copy()
+	slice.Clear()
+	assert.Equal(t, 0, slice.Length())
+	assert.Equal(t, []int{1}, copySlice.Values())
+}
+
+func TestCOWSliceConcurrentIterate(t *testing.T) {
+	slice := NewCOWSlice[int]()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			slice.Append(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			for range slice.Snapshot() {
+			}
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, 200, slice.Length())
+}
@@ -0,0 +1,403 @@
+package threadsafe
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Names of the files a DurableQueue keeps in its directory.
+const (
+	durableQueueOffset     = "offset"
+	durableQueueSegmentExt = ".seg"
+)
+
+// defaultSegmentSize is the segment size used when none is configured.
+const defaultSegmentSize = 4 << 20
+
+// offsetRecordSize is the size of the offset file: segment, position and CRC-32.
+const offsetRecordSize = 20
+
+// DurableQueueOptions configures a DurableQueue.
+// The zero value uses GobCodec, 4 MiB segments and syncs every operation.
+type DurableQueueOptions struct {
+	// Codec encodes queued items. Defaults to GobCodec.
+	Codec Codec
+	// SegmentSize is the size in bytes after which a new segment file is started.
+	// Defaults to 4 MiB.
+	SegmentSize int64
+	// NoSync skips fsync after each operation, trading durability for speed.
+	NoSync bool
+}
+
+// durableItem is a pending item and the position just past its record.
+type durableItem[T any] struct {
+	value T
+	seg   uint64
+	end   int64
+}
+
+// DurableQueue is a thread-safe FIFO queue persisted to segment files in a
+// directory. Enqueued items are appended to the newest segment; the position
+// of the consumer is stored separately, and fully consumed segments are
+// deleted. Pending items are recovered when the queue is reopened.
+type DurableQueue[T any] struct {
+	mu       mutex
+	dir      string
+	opts     DurableQueueOptions
+	items    []durableItem[T]
+	firstSeg uint64
+	writeSeg uint64
+	write    *os.File
+	writePos int64
+	offset   *os.File
+}
+
+// OpenDurableQueue opens or creates a durable queue stored in dir.
+// Example:
+//
+//	q, err := threadsafe.OpenDurableQueue[Job]("data/jobs", threadsafe.DurableQueueOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	defer q.Close()
+func OpenDurableQueue[T any](dir string, opts DurableQueueOptions) (*DurableQueue[T], error) {
+	if opts.Codec == nil {
+		opts.Codec = GobCodec
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &DurableQueue[T]{dir: dir, opts: opts}
+	if err := q.recover(); err != nil {
+		q.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// segmentPath returns the file name of segment seg.
+func (q *DurableQueue[T]) segmentPath(seg uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seg, durableQueueSegmentExt))
+}
+
+// segments lists the segment numbers present in the directory in ascending order.
+func (q *DurableQueue[T]) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, durableQueueSegmentExt) {
+			continue
+		}
+		seg, err := strconv.ParseUint(strings.TrimSuffix(name, durableQueueSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segs = append(segs, seg)
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i] < segs[j] })
+	return segs, nil
+}
+
+// readOffset returns the stored consumer position. A missing or corrupt
+// offset file yields the zero position, so items are redelivered rather than lost.
+func (q *DurableQueue[T]) readOffset() (uint64, int64) {
+	buf := make([]byte, offsetRecordSize)
+	if _, err := io.ReadFull(q.offset, buf); err != nil {
+		return 0, 0
+	}
+	if crc32.ChecksumIEEE(buf[:16]) != binary.LittleEndian.Uint32(buf[16:20]) {
+		return 0, 0
+	}
+	return binary.LittleEndian.Uint64(buf[0:8]), int64(binary.LittleEndian.Uint64(buf[8:16]))
+}
+
+// writeOffset stores the consumer position.
+func (q *DurableQueue[T]) writeOffset(seg uint64, pos int64) error {
+	buf := make([]byte, offsetRecordSize)
+	binary.LittleEndian.PutUint64(buf[0:8], seg)
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(pos))
+	binary.LittleEndian.PutUint32(buf[16:20], crc32.ChecksumIEEE(buf[:16]))
+	if _, err := q.offset.WriteAt(buf, 0); err != nil {
+		return err
+	}
+	if q.opts.NoSync {
+		return nil
+	}
+	return q.offset.Sync()
+}
+
+// recover loads pending items from the segments and opens the newest segment for appending.
+func (q *DurableQueue[T]) recover() error {
+	var err error
+	q.offset, err = os.OpenFile(filepath.Join(q.dir, durableQueueOffset), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	offSeg, offPos := q.readOffset()
+	segs, err := q.segments()
+	if err != nil {
+		return err
+	}
+	for i, seg := range segs {
+		if seg < offSeg {
+			if err := os.Remove(q.segmentPath(seg)); err != nil {
+				return err
+			}
+			continue
+		}
+		start := int64(0)
+		if seg == offSeg {
+			start = offPos
+		}
+		end, err := q.load(seg, start)
+		if err != nil {
+			return err
+		}
+		if i == len(segs)-1 {
+			q.writeSeg, q.writePos = seg, end
+		}
+	}
+	if len(q.items) > 0 {
+		q.firstSeg = q.items[0].seg
+	} else {
+		q.firstSeg = q.writeSeg
+	}
+	if q.writeSeg < offSeg {
+		q.firstSeg, q.writeSeg, q.writePos = offSeg, offSeg, 0
+	}
+	q.write, err = os.OpenFile(q.segmentPath(q.writeSeg), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := q.write.Truncate(q.writePos); err != nil {
+		return err
+	}
+	if _, err := q.write.Seek(q.writePos, io.SeekStart); err != nil {
+		return err
+	}
+	return q.syncDir()
+}
+
+// load reads the records of segment seg from position start into q.items.
+// It returns the position after the last intact record, where a torn or
+// corrupt tail begins. A record that passes its checksum but cannot be
+// decoded is returned as an error, so that recover leaves the file alone.
+func (q *DurableQueue[T]) load(seg uint64, start int64) (int64, error) {
+	f, err := os.Open(q.segmentPath(seg))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if start > info.Size() {
+		return info.Size(), nil
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r := bufio.NewReader(f)
+	pos := start
+	for {
+		payload, err := readRecord(r, info.Size()-pos)
+		if err == io.EOF || err == errBadRecord {
+			return pos, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		var value T
+		if err := q.opts.Codec.Unmarshal(payload, &value); err != nil {
+			return 0, fmt.Errorf("threadsafe: decoding segment %d at offset %d: %w", seg, pos, err)
+		}
+		pos += int64(recordHeaderSize + len(payload))
+		q.items = append(q.items, durableItem[T]{value: value, seg: seg, end: pos})
+	}
+}
+
+// Enqueue durably adds an element to the queue.
+// Example:
+//
+//	err := q.Enqueue(job)
+func (q *DurableQueue[T]) Enqueue(value T) error {
+	q.mu.count("Enqueue")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.write == nil {
+		return ErrClosed
+	}
+	payload, err := q.opts.Codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if q.writePos > 0 && q.writePos >= q.opts.SegmentSize {
+		if err := q.rotate(); err != nil {
+			return err
+		}
+	}
+	buf := frameRecord(payload)
+	if _, err := q.write.Write(buf); err != nil {
+		return q.rollback(err)
+	}
+	if !q.opts.NoSync {
+		if err := q.write.Sync(); err != nil {
+			return q.rollback(err)
+		}
+	}
+	q.writePos += int64(len(buf))
+	q.items = append(q.items, durableItem[T]{value: value, seg: q.writeSeg, end: q.writePos})
+	return nil
+}
+
+// rollback discards whatever part of a failed append reached the current
+// segment, so that the file stays in step with writePos and the offsets of
+// later items. It returns err, joined with any error from the rollback itself.
+func (q *DurableQueue[T]) rollback(err error) error {
+	if terr := q.write.Truncate(q.writePos); terr != nil {
+		return errors.Join(err, terr)
+	}
+	if _, serr := q.write.Seek(q.writePos, io.SeekStart); serr != nil {
+		return errors.Join(err, serr)
+	}
+	return err
+}
+
+// rotate closes the current segment and starts the next one.
+// Unless NoSync is set, the directory is synced so the new segment, and the
+// items about to be written to it, survive a crash.
+func (q *DurableQueue[T]) rotate() error {
+	if err := q.write.Close(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(q.segmentPath(q.writeSeg+1), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		q.write = nil
+		return err
+	}
+	q.write, q.writeSeg, q.writePos = f, q.writeSeg+1, 0
+	return q.syncDir()
+}
+
+// syncDir makes created and deleted segment files durable, unless NoSync is set.
+func (q *DurableQueue[T]) syncDir() error {
+	if q.opts.NoSync {
+		return nil
+	}
+	return syncDir(q.dir)
+}
+
+// Dequeue durably removes and returns an element from the queue.
+// The boolean is false if the queue is empty.
+// Example:
+//
+//	job, ok, err := q.Dequeue()
+func (q *DurableQueue[T]) Dequeue() (T, bool, error) {
+	q.mu.count("Dequeue")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var zero T
+	if q.write == nil {
+		return zero, false, ErrClosed
+	}
+	if len(q.items) == 0 {
+		return zero, false, nil
+	}
+	item := q.items[0]
+	if err := q.writeOffset(item.seg, item.end); err != nil {
+		return zero, false, err
+	}
+	q.items[0] = durableItem[T]{}
+	q.items = q.items[1:]
+	next := q.writeSeg
+	if len(q.items) > 0 {
+		next = q.items[0].seg
+	}
+	if q.firstSeg >= next {
+		return item.value, true, nil
+	}
+	for ; q.firstSeg < next; q.firstSeg++ {
+		if err := os.Remove(q.segmentPath(q.firstSeg)); err != nil && !os.IsNotExist(err) {
+			return item.value, true, err
+		}
+	}
+	return item.value, true, q.syncDir()
+}
+
+// Peek returns the element at the front of the queue without removing it.
+// Example:
+//
+//	job, ok := q.Peek()
+func (q *DurableQueue[T]) Peek() (T, bool) {
+	q.mu.count("Peek")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.items[0].value, true
+}
+
+// Len returns the number of elements in the queue.
+// Example:
+//
+//	length := q.Len()
+func (q *DurableQueue[T]) Len() int {
+	q.mu.count("Len")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// IsEmpty checks if the queue is empty.
+// Example:
+//
+//	isEmpty := q.IsEmpty()
+func (q *DurableQueue[T]) IsEmpty() bool {
+	return q.Len() == 0
+}
+
+// Close syncs and closes the queue's files. Further Enqueue and Dequeue calls return ErrClosed.
+// Example:
+//
+//	err := q.Close()
+func (q *DurableQueue[T]) Close() error {
+	q.mu.count("Close")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.write == nil && q.offset == nil {
+		return ErrClosed
+	}
+	var err error
+	for _, f := range []*os.File{q.write, q.offset} {
+		if f == nil {
+			continue
+		}
+		if serr := f.Sync(); err == nil {
+			err = serr
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	q.write, q.offset = nil, nil
+	return err
+}
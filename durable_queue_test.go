@@ -0,0 +1,230 @@
+package threadsafe
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurableQueueEnqueueDequeue(t *testing.T) {
+	q, err := OpenDurableQueue[string](t.TempDir(), DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	assert.True(t, q.IsEmpty())
+	assert.NoError(t, q.Enqueue("a"))
+	assert.NoError(t, q.Enqueue("b"))
+	assert.Equal(t, 2, q.Len())
+	value, ok := q.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "a", value)
+	value, ok, err = q.Dequeue()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", value)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestDurableQueueDequeueEmpty(t *testing.T) {
+	q, err := OpenDurableQueue[int](t.TempDir(), DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	value, ok, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 0, value)
+}
+
+func TestDurableQueueRecover(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, q.Enqueue(i))
+	}
+	_, _, err = q.Dequeue()
+	assert.NoError(t, err)
+	assert.NoError(t, q.Close())
+
+	q, err = OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, q.Len())
+	assert.NoError(t, q.Enqueue(4))
+	assert.NoError(t, q.Close())
+
+	q, err = OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	var values []int
+	for {
+		value, ok, err := q.Dequeue()
+		assert.NoError(t, err)
+		if !ok {
+			break
+		}
+		values = append(values, value)
+	}
+	assert.Equal(t, []int{2, 3, 4}, values)
+}
+
+func TestDurableQueueSegments(t *testing.T) {
+	dir := t.TempDir()
+	opts := DurableQueueOptions{Codec: JSONCodec, SegmentSize: 32, NoSync: true}
+	q, err := OpenDurableQueue[int](dir, opts)
+	assert.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, q.Enqueue(i))
+	}
+	segs, err := q.segments()
+	assert.NoError(t, err)
+	assert.Greater(t, len(segs), 1)
+
+	for i := 0; i < 15; i++ {
+		value, ok, err := q.Dequeue()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+	remaining, err := q.segments()
+	assert.NoError(t, err)
+	assert.Less(t, len(remaining), len(segs))
+	assert.NoError(t, q.Close())
+
+	q, err = OpenDurableQueue[int](dir, opts)
+	assert.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, 5, q.Len())
+	value, _ := q.Peek()
+	assert.Equal(t, 15, value)
+}
+
+func TestDurableQueueTornTail(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[string](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, q.Enqueue("a"))
+	assert.NoError(t, q.Enqueue("b"))
+	assert.NoError(t, q.Close())
+
+	path := q.segmentPath(0)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(path, info.Size()-2))
+
+	q, err = OpenDurableQueue[string](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, 1, q.Len())
+	assert.NoError(t, q.Enqueue("c"))
+	value, _, _ := q.Dequeue()
+	assert.Equal(t, "a", value)
+	value, _, _ = q.Dequeue()
+	assert.Equal(t, "c", value)
+}
+
+func TestDurableQueueDecodeError(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, q.Enqueue(1))
+	assert.NoError(t, q.Close())
+	path := q.segmentPath(0)
+	before, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	_, err = OpenDurableQueue[string](dir, DurableQueueOptions{})
+	assert.Error(t, err)
+	after, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	q, err = OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	value, _, _ := q.Dequeue()
+	assert.Equal(t, 1, value)
+}
+
+func TestDurableQueueRollbackFailedAppend(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[string](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, q.Enqueue("a"))
+
+	// Simulate an append that failed after writing part of a record.
+	_, err = q.write.Write([]byte{0xff, 0xff, 0xff})
+	assert.NoError(t, err)
+	assert.EqualError(t, q.rollback(errors.New("disk full")), "disk full")
+
+	assert.NoError(t, q.Enqueue("b"))
+	value, _, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+	assert.NoError(t, q.Close())
+
+	q, err = OpenDurableQueue[string](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	value, ok, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", value)
+}
+
+func TestDurableQueueCorruptOffset(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, q.Enqueue(1))
+	assert.NoError(t, q.Enqueue(2))
+	_, _, err = q.Dequeue()
+	assert.NoError(t, err)
+	assert.NoError(t, q.Close())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, durableQueueOffset), []byte("garbage"), 0o644))
+	q, err = OpenDurableQueue[int](dir, DurableQueueOptions{})
+	assert.NoError(t, err)
+	defer q.Close()
+	// Without a valid offset, items are redelivered rather than lost.
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestDurableQueueClosed(t *testing.T) {
+	q, err := OpenDurableQueue[int](t.TempDir(), DurableQueueOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, q.Close())
+	assert.ErrorIs(t, q.Enqueue(1), ErrClosed)
+	_, _, err = q.Dequeue()
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, q.Close(), ErrClosed)
+}
+
+func TestDurableQueueConcurrent(t *testing.T) {
+	q, err := OpenDurableQueue[int](t.TempDir(), DurableQueueOptions{SegmentSize: 256, NoSync: true})
+	assert.NoError(t, err)
+	defer q.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, q.Enqueue(i*50+j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	for {
+		value, ok, err := q.Dequeue()
+		assert.NoError(t, err)
+		if !ok {
+			break
+		}
+		seen[value] = true
+	}
+	assert.Len(t, seen, 200)
+}
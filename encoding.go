@@ -0,0 +1,225 @@
+package threadsafe
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// encodingVersion is the current version of the binary encoding format.
+// Decoders reject data written by a newer version.
+const encodingVersion = 1
+
+// Kinds of encoded payloads. Array, Slice and COWSlice share a layout,
+// as do Map and COWMap, so data can be decoded into either type.
+const (
+	kindList byte = iota + 1
+	kindMap
+	kindQueue
+	kindStack
+)
+
+// encodingMagic starts every binary-encoded collection.
+var encodingMagic = [2]byte{'t', 's'}
+
+// ErrInvalidEncoding is returned when decoding data that was not produced by
+// this package's MarshalBinary or GobEncode, or that belongs to another kind of collection.
+var ErrInvalidEncoding = errors.New("threadsafe: invalid binary encoding")
+
+// encodeBinary writes the versioned header for kind followed by the gob encoding of v.
+func encodeBinary(kind byte, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(encodingMagic[:])
+	buf.WriteByte(encodingVersion)
+	buf.WriteByte(kind)
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeBinary checks the header of b against kind and gob-decodes the payload into v.
+func decodeBinary(b []byte, kind byte, v interface{}) error {
+	if len(b) < 4 || b[0] != encodingMagic[0] || b[1] != encodingMagic[1] {
+		return ErrInvalidEncoding
+	}
+	if b[2] == 0 || b[2] > encodingVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, b[2])
+	}
+	if b[3] != kind {
+		return fmt.Errorf("%w: unexpected kind %d", ErrInvalidEncoding, b[3])
+	}
+	return gob.NewDecoder(bytes.NewReader(b[4:])).Decode(v)
+}
+
+// MarshalBinary encodes the array's contents.
+func (a *Array[T]) MarshalBinary() ([]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return encodeBinary(kindList, a.data)
+}
+
+// UnmarshalBinary replaces the array's contents with data produced by MarshalBinary.
+func (a *Array[T]) UnmarshalBinary(b []byte) error {
+	data := []T{}
+	if err := decodeBinary(b, kindList, &data); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.data = data
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (a *Array[T]) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (a *Array[T]) GobDecode(b []byte) error { return a.UnmarshalBinary(b) }
+
+// MarshalBinary encodes the slice's contents.
+func (s *Slice[T]) MarshalBinary() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return encodeBinary(kindList, s.data)
+}
+
+// UnmarshalBinary replaces the slice's contents with data produced by MarshalBinary.
+func (s *Slice[T]) UnmarshalBinary(b []byte) error {
+	data := []T{}
+	if err := decodeBinary(b, kindList, &data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (s *Slice[T]) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (s *Slice[T]) GobDecode(b []byte) error { return s.UnmarshalBinary(b) }
+
+// MarshalBinary encodes the slice's contents.
+func (s *COWSlice[T]) MarshalBinary() ([]byte, error) {
+	return encodeBinary(kindList, s.load())
+}
+
+// UnmarshalBinary replaces the slice's contents with data produced by MarshalBinary.
+func (s *COWSlice[T]) UnmarshalBinary(b []byte) error {
+	data := []T{}
+	if err := decodeBinary(b, kindList, &data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(data)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (s *COWSlice[T]) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (s *COWSlice[T]) GobDecode(b []byte) error { return s.UnmarshalBinary(b) }
+
+// MarshalBinary encodes the map's contents.
+func (m *Map[K, V]) MarshalBinary() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return encodeBinary(kindMap, m.data)
+}
+
+// UnmarshalBinary replaces the map's contents with data produced by MarshalBinary.
+func (m *Map[K, V]) UnmarshalBinary(b []byte) error {
+	data := make(map[K]V)
+	if err := decodeBinary(b, kindMap, &data); err != nil {
+		return err
+	}
+	m.replace(data)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (m *Map[K, V]) GobEncode() ([]byte, error) { return m.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (m *Map[K, V]) GobDecode(b []byte) error { return m.UnmarshalBinary(b) }
+
+// MarshalBinary encodes the map's contents.
+func (m *COWMap[K, V]) MarshalBinary() ([]byte, error) {
+	return encodeBinary(kindMap, m.snapshot())
+}
+
+// UnmarshalBinary replaces the map's contents with data produced by MarshalBinary.
+func (m *COWMap[K, V]) UnmarshalBinary(b []byte) error {
+	data := make(map[K]V)
+	if err := decodeBinary(b, kindMap, &data); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.Store(&data)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (m *COWMap[K, V]) GobEncode() ([]byte, error) { return m.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (m *COWMap[K, V]) GobDecode(b []byte) error { return m.UnmarshalBinary(b) }
+
+// MarshalBinary encodes the queue's contents from front to back.
+// Concrete element types other than gob's built-in types must be registered with gob.Register.
+func (q *Queue) MarshalBinary() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return encodeBinary(kindQueue, q.values())
+}
+
+// UnmarshalBinary replaces the queue's contents with data produced by MarshalBinary.
+func (q *Queue) UnmarshalBinary(b []byte) error {
+	var values []interface{}
+	if err := decodeBinary(b, kindQueue, &values); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reset(values)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (q *Queue) GobEncode() ([]byte, error) { return q.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (q *Queue) GobDecode(b []byte) error { return q.UnmarshalBinary(b) }
+
+// MarshalBinary encodes the stack's contents from top to bottom.
+// Concrete element types other than gob's built-in types must be registered with gob.Register.
+func (s *Stack) MarshalBinary() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return encodeBinary(kindStack, s.values())
+}
+
+// UnmarshalBinary replaces the stack's contents with data produced by MarshalBinary.
+func (s *Stack) UnmarshalBinary(b []byte) error {
+	var values []interface{}
+	if err := decodeBinary(b, kindStack, &values); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset(values)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (s *Stack) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (s *Stack) GobDecode(b []byte) error { return s.UnmarshalBinary(b) }
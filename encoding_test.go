@@ -0,0 +1,95 @@
+package threadsafe
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapGob(t *testing.T) {
+	type checkpoint struct {
+		Counts *Map[string, int]
+		Names  *Slice[string]
+	}
+	in := checkpoint{Counts: NewMap[string, int](), Names: NewSlice[string]()}
+	in.Counts.Set("a", 1)
+	in.Names.Append("x")
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(in))
+	var out checkpoint
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+	value, ok := out.Counts.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, []string{"x"}, out.Names.Values())
+}
+
+func TestArrayBinary(t *testing.T) {
+	arr := NewArray[int](2)
+	arr.Set(0, 7)
+	b, err := arr.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := NewArray[int](0)
+	assert.NoError(t, decoded.UnmarshalBinary(b))
+	assert.Equal(t, []int{7, 0}, decoded.Values())
+
+	// Array and Slice share a layout.
+	slice := NewSlice[int]()
+	assert.NoError(t, slice.UnmarshalBinary(b))
+	assert.Equal(t, []int{7, 0}, slice.Values())
+}
+
+func TestCOWBinary(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	b, err := m.MarshalBinary()
+	assert.NoError(t, err)
+	cowMap := NewCOWMap[string, int]()
+	assert.NoError(t, cowMap.UnmarshalBinary(b))
+	assert.True(t, cowMap.Contains("a"))
+
+	cowSlice := NewCOWSlice[int]()
+	cowSlice.Append(3)
+	b, err = cowSlice.GobEncode()
+	assert.NoError(t, err)
+	decoded := NewCOWSlice[int]()
+	assert.NoError(t, decoded.GobDecode(b))
+	assert.Equal(t, []int{3}, decoded.Values())
+}
+
+func TestQueueStackBinary(t *testing.T) {
+	queue := NewQueue()
+	queue.Enqueue(1)
+	queue.Enqueue("two")
+	b, err := queue.MarshalBinary()
+	assert.NoError(t, err)
+	decodedQueue := NewQueue()
+	assert.NoError(t, decodedQueue.UnmarshalBinary(b))
+	assert.Equal(t, []interface{}{1, "two"}, decodedQueue.Values())
+
+	stack := NewStack()
+	stack.Push(1)
+	stack.Push(2)
+	b, err = stack.MarshalBinary()
+	assert.NoError(t, err)
+	decodedStack := NewStack()
+	assert.NoError(t, decodedStack.UnmarshalBinary(b))
+	assert.Equal(t, []interface{}{2, 1}, decodedStack.Values())
+}
+
+func TestBinaryInvalidHeader(t *testing.T) {
+	m := NewMap[string, int]()
+	assert.True(t, errors.Is(m.UnmarshalBinary([]byte("nope")), ErrInvalidEncoding))
+	assert.True(t, errors.Is(m.UnmarshalBinary(nil), ErrInvalidEncoding))
+
+	b, err := NewSlice[int]().MarshalBinary()
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(m.UnmarshalBinary(b), ErrInvalidEncoding))
+
+	b[2] = encodingVersion + 1
+	assert.True(t, errors.Is(NewSlice[int]().UnmarshalBinary(b), ErrInvalidEncoding))
+}
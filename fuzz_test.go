@@ -0,0 +1,227 @@
+package threadsafe
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzOp is one decoded operation: a selector, a possibly negative index or
+// key, and a value.
+type fuzzOp struct {
+	code  int
+	index int
+	value int
+}
+
+// decodeOps splits data into three-byte operations.
+func decodeOps(data []byte, codes int) []fuzzOp {
+	ops := make([]fuzzOp, 0, len(data)/3)
+	for len(data) >= 3 {
+		ops = append(ops, fuzzOp{code: int(data[0]) % codes, index: int(int8(data[1])), value: int(data[2])})
+		data = data[3:]
+	}
+	return ops
+}
+
+// fuzzSeeds adds inputs that exercise boundary indexes to every target.
+func fuzzSeeds(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 1, 0, 0, 2, 0, 0, 3})
+	// Append twice, then insert at len(data) and remove the last index.
+	f.Add([]byte{0, 0, 1, 0, 0, 2, 4, 2, 9, 3, 2, 0, 3, 1, 0})
+	// Negative and out-of-range indexes.
+	f.Add([]byte{0, 0, 1, 1, 0xff, 0, 2, 5, 7, 3, 0x80, 0, 4, 0x7f, 7})
+	f.Add([]byte{0, 0, 1, 0, 0, 1, 5, 0, 1, 6, 0, 0, 7, 0, 0, 1, 0, 0})
+}
+
+// fuzzList is the method set shared by Array and Slice.
+type fuzzList interface {
+	Append(value int)
+	Get(index int) (int, bool)
+	Set(index int, value int) bool
+	Remove(index int) bool
+	Insert(index int, value int) bool
+	Contains(value int) bool
+	Length() int
+	Values() []int
+	Clear()
+}
+
+// checkList applies ops to l and to a plain slice, comparing every result.
+func checkList(t *testing.T, l fuzzList, ref []int, ops []fuzzOp) {
+	for _, op := range ops {
+		switch op.code {
+		case 0:
+			l.Append(op.value)
+			ref = append(ref, op.value)
+		case 1:
+			got, ok := l.Get(op.index)
+			inRange := op.index >= 0 && op.index < len(ref)
+			assert.Equal(t, inRange, ok, "Get(%d)", op.index)
+			if inRange {
+				assert.Equal(t, ref[op.index], got, "Get(%d)", op.index)
+			}
+		case 2:
+			inRange := op.index >= 0 && op.index < len(ref)
+			assert.Equal(t, inRange, l.Set(op.index, op.value), "Set(%d)", op.index)
+			if inRange {
+				ref[op.index] = op.value
+			}
+		case 3:
+			inRange := op.index >= 0 && op.index < len(ref)
+			assert.Equal(t, inRange, l.Remove(op.index), "Remove(%d)", op.index)
+			if inRange {
+				ref = append(ref[:op.index:op.index], ref[op.index+1:]...)
+			}
+		case 4:
+			inRange := op.index >= 0 && op.index <= len(ref)
+			assert.Equal(t, inRange, l.Insert(op.index, op.value), "Insert(%d)", op.index)
+			if inRange {
+				next := make([]int, 0, len(ref)+1)
+				next = append(next, ref[:op.index]...)
+				next = append(next, op.value)
+				ref = append(next, ref[op.index:]...)
+			}
+		case 5:
+			want := false
+			for _, v := range ref {
+				want = want || v == op.value
+			}
+			assert.Equal(t, want, l.Contains(op.value), "Contains(%d)", op.value)
+		case 6:
+			l.Clear()
+			ref = nil
+		case 7:
+			assert.Equal(t, len(ref), l.Length())
+		}
+		if !assert.Equal(t, len(ref), len(l.Values())) {
+			return
+		}
+		for i, v := range l.Values() {
+			assert.Equal(t, ref[i], v, "index %d", i)
+		}
+	}
+}
+
+func FuzzArray(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		size := 0
+		if len(data) > 0 {
+			size, data = int(data[0])%8, data[1:]
+		}
+		checkList(t, NewArray[int](size), make([]int, size), decodeOps(data, 8))
+	})
+}
+
+func FuzzSlice(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkList(t, NewSlice[int](), nil, decodeOps(data, 8))
+	})
+}
+
+func FuzzMap(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m := NewMap[int, int]()
+		ref := make(map[int]int)
+		for _, op := range decodeOps(data, 6) {
+			key := op.index % 16
+			switch op.code {
+			case 0:
+				m.Set(key, op.value)
+				ref[key] = op.value
+			case 1:
+				got, ok := m.Get(key)
+				want, exists := ref[key]
+				assert.Equal(t, exists, ok, "Get(%d)", key)
+				assert.Equal(t, want, got, "Get(%d)", key)
+			case 2:
+				m.Delete(key)
+				delete(ref, key)
+			case 3:
+				_, exists := ref[key]
+				assert.Equal(t, exists, m.Contains(key), "Contains(%d)", key)
+			case 4:
+				m.Clear()
+				ref = make(map[int]int)
+			case 5:
+				assert.Equal(t, len(ref), m.Length())
+			}
+			keys := m.Keys()
+			sort.Ints(keys)
+			want := make([]int, 0, len(ref))
+			for k := range ref {
+				want = append(want, k)
+			}
+			sort.Ints(want)
+			assert.Equal(t, want, keys)
+		}
+	})
+}
+
+// checkSequence applies ops to a queue or stack and to a plain slice holding
+// the elements in removal order.
+func checkSequence(t *testing.T, lifo bool, add func(interface{}), remove, peek func() (interface{}, bool), length func() int, isEmpty func() bool, clear func(), values func() []interface{}, ops []fuzzOp) {
+	var ref []int
+	for _, op := range ops {
+		switch op.code {
+		case 0:
+			add(op.value)
+			if lifo {
+				ref = append([]int{op.value}, ref...)
+			} else {
+				ref = append(ref, op.value)
+			}
+		case 1, 2:
+			var got interface{}
+			var ok bool
+			if op.code == 1 {
+				got, ok = remove()
+			} else {
+				got, ok = peek()
+			}
+			if !assert.Equal(t, len(ref) > 0, ok) {
+				return
+			}
+			if ok {
+				assert.Equal(t, ref[0], got)
+				if op.code == 1 {
+					ref = ref[1:]
+				}
+			}
+		case 3:
+			assert.Equal(t, len(ref), length())
+			assert.Equal(t, len(ref) == 0, isEmpty())
+		case 4:
+			clear()
+			ref = nil
+		}
+		got := values()
+		if !assert.Equal(t, len(ref), len(got)) {
+			return
+		}
+		for i, v := range got {
+			assert.Equal(t, ref[i], v, "index %d", i)
+		}
+	}
+}
+
+func FuzzQueue(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		q := NewQueue()
+		checkSequence(t, false, q.Enqueue, q.Dequeue, q.Peek, q.Len, q.IsEmpty, q.Clear, q.Values, decodeOps(data, 5))
+	})
+}
+
+func FuzzStack(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		s := NewStack()
+		checkSequence(t, true, s.Push, s.Pop, s.Peek, s.Len, s.IsEmpty, s.Clear, s.Values, decodeOps(data, 5))
+	})
+}
@@ -0,0 +1,247 @@
+package threadsafe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON encodes the array as a JSON array.
+func (a *Array[T]) MarshalJSON() ([]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return json.Marshal(a.data)
+}
+
+// UnmarshalJSON replaces the array's contents with the decoded JSON array.
+func (a *Array[T]) UnmarshalJSON(b []byte) error {
+	data := []T{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.data = data
+	return nil
+}
+
+// MarshalJSON encodes the slice as a JSON array.
+func (s *Slice[T]) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return json.Marshal(s.data)
+}
+
+// UnmarshalJSON replaces the slice's contents with the decoded JSON array.
+func (s *Slice[T]) UnmarshalJSON(b []byte) error {
+	data := []T{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	return nil
+}
+
+// MarshalJSON encodes the slice as a JSON array.
+func (s *COWSlice[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.load())
+}
+
+// UnmarshalJSON replaces the slice's contents with the decoded JSON array.
+func (s *COWSlice[T]) UnmarshalJSON(b []byte) error {
+	data := []T{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(data)
+	return nil
+}
+
+// MarshalJSON encodes the map as a JSON object.
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return json.Marshal(m.data)
+}
+
+// UnmarshalJSON replaces the map's contents with the decoded JSON object.
+func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
+	data := make(map[K]V)
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if data == nil {
+		// JSON null decodes to a nil map; treat it as an empty object.
+		data = make(map[K]V)
+	}
+	m.replace(data)
+	return nil
+}
+
+// MarshalJSON encodes the map as a JSON object.
+func (m *COWMap[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.snapshot())
+}
+
+// UnmarshalJSON replaces the map's contents with the decoded JSON object.
+func (m *COWMap[K, V]) UnmarshalJSON(b []byte) error {
+	data := make(map[K]V)
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if data == nil {
+		// JSON null decodes to a nil map; treat it as an empty object.
+		data = make(map[K]V)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.Store(&data)
+	return nil
+}
+
+// MarshalJSON encodes the map as a JSON object whose members appear from
+// oldest to newest. Keys are encoded the way encoding/json encodes map keys.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for e := m.root.next; e != &m.root; e = e.next {
+		if e != m.root.next {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSONKey(e.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the map's contents with the decoded JSON object,
+// keeping the order in which its members appear.
+func (m *OrderedMap[K, V]) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	var keys []K
+	var values []V
+	if tok != nil {
+		if d, ok := tok.(json.Delim); !ok || d != '{' {
+			return fmt.Errorf("threadsafe: cannot unmarshal %v into an OrderedMap", tok)
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			quoted, err := json.Marshal(tok)
+			if err != nil {
+				return err
+			}
+			key, err := unmarshalJSONKey[K](quoted)
+			if err != nil {
+				return err
+			}
+			var value V
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reset()
+	for i, key := range keys {
+		m.set(key, values[i])
+	}
+	return nil
+}
+
+// marshalJSONKey encodes key as the quoted JSON string encoding/json uses
+// for it as a map key.
+func marshalJSONKey[K comparable](key K) ([]byte, error) {
+	b, err := json.Marshal(map[K]struct{}{key: {}})
+	if err != nil {
+		return nil, err
+	}
+	// b is {"key":{}}.
+	return b[1 : len(b)-len(":{}}")], nil
+}
+
+// unmarshalJSONKey decodes a quoted JSON string the way encoding/json
+// decodes map keys.
+func unmarshalJSONKey[K comparable](quoted []byte) (K, error) {
+	doc := make([]byte, 0, len(quoted)+5)
+	doc = append(doc, '{')
+	doc = append(doc, quoted...)
+	doc = append(doc, ":{}}"...)
+	var m map[K]struct{}
+	err := json.Unmarshal(doc, &m)
+	for key := range m {
+		return key, err
+	}
+	var zero K
+	return zero, err
+}
+
+// MarshalJSON encodes the queue as a JSON array from front to back.
+func (q *Queue) MarshalJSON() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return json.Marshal(q.values())
+}
+
+// UnmarshalJSON replaces the queue's contents with the decoded JSON array,
+// whose first element becomes the front of the queue.
+// Elements are decoded as by json.Unmarshal into an interface{} value.
+func (q *Queue) UnmarshalJSON(b []byte) error {
+	var values []interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reset(values)
+	return nil
+}
+
+// MarshalJSON encodes the stack as a JSON array from top to bottom.
+func (s *Stack) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.Marshal(s.values())
+}
+
+// UnmarshalJSON replaces the stack's contents with the decoded JSON array,
+// whose first element becomes the top of the stack.
+// Elements are decoded as by json.Unmarshal into an interface{} value.
+func (s *Stack) UnmarshalJSON(b []byte) error {
+	var values []interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset(values)
+	return nil
+}
@@ -0,0 +1,127 @@
+package threadsafe
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayJSON(t *testing.T) {
+	arr := NewArray[int](3)
+	arr.Set(1, 2)
+	b, err := json.Marshal(arr)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[0,2,0]`, string(b))
+
+	decoded := NewArray[int](0)
+	assert.NoError(t, json.Unmarshal(b, decoded))
+	assert.Equal(t, []int{0, 2, 0}, decoded.Values())
+}
+
+func TestSliceJSON(t *testing.T) {
+	slice := NewSlice[string]()
+	b, err := json.Marshal(slice)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(b))
+
+	slice.Append("a")
+	slice.Append("b")
+	b, err = json.Marshal(slice)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["a","b"]`, string(b))
+
+	decoded := NewSlice[string]()
+	assert.NoError(t, json.Unmarshal(b, decoded))
+	assert.Equal(t, []string{"a", "b"}, decoded.Values())
+}
+
+func TestMapJSONEmbedded(t *testing.T) {
+	type response struct {
+		Counts *Map[string, int] `json:"counts"`
+		Tags   *Slice[string]    `json:"tags"`
+	}
+	r := response{Counts: NewMap[string, int](), Tags: NewSlice[string]()}
+	r.Counts.Set("a", 1)
+	r.Tags.Append("x")
+	b, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"counts":{"a":1},"tags":["x"]}`, string(b))
+
+	var decoded response
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	value, ok := decoded.Counts.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, []string{"x"}, decoded.Tags.Values())
+}
+
+func TestMapJSONInvalid(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"x"}`), m))
+	value, _ := m.Get("a")
+	assert.Equal(t, 1, value)
+}
+
+func TestMapJSONNull(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	assert.NoError(t, json.Unmarshal([]byte(`null`), m))
+	assert.Equal(t, 0, m.Length())
+	m.Set("b", 2)
+	assert.True(t, m.Contains("b"))
+
+	c := NewCOWMap[string, int]()
+	assert.NoError(t, json.Unmarshal([]byte(`null`), c))
+	c.Set("b", 2)
+	assert.True(t, c.Contains("b"))
+}
+
+func TestCOWJSON(t *testing.T) {
+	m := NewCOWMap[string, int]()
+	m.Set("a", 1)
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	decodedMap := NewCOWMap[string, int]()
+	assert.NoError(t, json.Unmarshal(b, decodedMap))
+	assert.True(t, decodedMap.Contains("a"))
+
+	slice := NewCOWSlice[int]()
+	slice.Append(1)
+	b, err = json.Marshal(slice)
+	assert.NoError(t, err)
+	decodedSlice := NewCOWSlice[int]()
+	assert.NoError(t, json.Unmarshal(b, decodedSlice))
+	assert.Equal(t, []int{1}, decodedSlice.Values())
+}
+
+func TestQueueJSON(t *testing.T) {
+	queue := NewQueue()
+	queue.Enqueue("first")
+	queue.Enqueue("second")
+	b, err := json.Marshal(queue)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["first","second"]`, string(b))
+
+	decoded := NewQueue()
+	assert.NoError(t, json.Unmarshal(b, decoded))
+	value, _ := decoded.Dequeue()
+	assert.Equal(t, "first", value)
+}
+
+func TestStackJSON(t *testing.T) {
+	stack := NewStack()
+	stack.Push("bottom")
+	stack.Push("top")
+	b, err := json.Marshal(stack)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["top","bottom"]`, string(b))
+
+	decoded := NewStack()
+	assert.NoError(t, json.Unmarshal(b, decoded))
+	value, _ := decoded.Pop()
+	assert.Equal(t, "top", value)
+	value, _ = decoded.Pop()
+	assert.Equal(t, "bottom", value)
+}
@@ -0,0 +1,274 @@
+// Package linearizability records concurrent operation histories and checks
+// them against sequential models.
+//
+// A history is linearizable if every operation can be assigned a single
+// instant between its call and its return such that applying the operations
+// in that order to the model produces the recorded outputs. The checker
+// follows the Wing & Gong search with the memoization described by Lowe,
+// as popularized by Porcupine.
+//
+// Example:
+//
+//	rec := linearizability.NewRecorder[regInput, regOutput]()
+//	// from each client goroutine:
+//	rec.Record(client, input, func() regOutput { ... })
+//	ok := linearizability.Check(model, rec.History())
+package linearizability
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Operation is a single completed call in a history.
+// Call and Return are timestamps in nanoseconds on a shared monotonic clock.
+type Operation[I, O any] struct {
+	ClientID int
+	Input    I
+	Output   O
+	Call     int64
+	Return   int64
+}
+
+// Model is the sequential specification histories are checked against.
+// S is the model state, I the operation input and O its output.
+type Model[S, I, O any] struct {
+	// Partition optionally splits a history into independent histories,
+	// such as one per key, that are checked separately.
+	Partition func(history []Operation[I, O]) [][]Operation[I, O]
+	// Init returns the initial state.
+	Init func() S
+	// Step applies input to state. It reports whether output is a legal
+	// result and returns the new state. Step must not modify state.
+	Step func(state S, input I, output O) (bool, S)
+	// Equal reports whether two states are equal. Defaults to reflect.DeepEqual.
+	Equal func(a, b S) bool
+}
+
+// Check reports whether history is linearizable with respect to model.
+// Example:
+//
+//	ok := linearizability.Check(registerModel, rec.History())
+func Check[S, I, O any](model Model[S, I, O], history []Operation[I, O]) bool {
+	if model.Equal == nil {
+		model.Equal = func(a, b S) bool { return reflect.DeepEqual(a, b) }
+	}
+	partitions := [][]Operation[I, O]{history}
+	if model.Partition != nil {
+		partitions = model.Partition(history)
+	}
+	for _, p := range partitions {
+		if !checkSingle(model, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// entry is a call or return event in the doubly linked list the search works on.
+type entry[I, O any] struct {
+	id     int
+	isCall bool
+	input  I
+	output O
+	time   int64
+	match  *entry[I, O]
+	prev   *entry[I, O]
+	next   *entry[I, O]
+}
+
+// makeEntries returns the events of history in time order behind a sentinel head.
+// A call and a return with the same timestamp are treated as concurrent.
+func makeEntries[I, O any](history []Operation[I, O]) *entry[I, O] {
+	events := make([]*entry[I, O], 0, 2*len(history))
+	for i, op := range history {
+		call := &entry[I, O]{id: i, isCall: true, input: op.Input, time: op.Call}
+		ret := &entry[I, O]{id: i, output: op.Output, time: op.Return}
+		call.match = ret
+		events = append(events, call, ret)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].time != events[j].time {
+			return events[i].time < events[j].time
+		}
+		return events[i].isCall && !events[j].isCall
+	})
+	head := &entry[I, O]{id: -1}
+	prev := head
+	for _, e := range events {
+		prev.next = e
+		e.prev = prev
+		prev = e
+	}
+	return head
+}
+
+// lift removes a call and its return from the list.
+func lift[I, O any](e *entry[I, O]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	m := e.match
+	m.prev.next = m.next
+	if m.next != nil {
+		m.next.prev = m.prev
+	}
+}
+
+// unlift reinserts a call and its return removed by lift.
+func unlift[I, O any](e *entry[I, O]) {
+	m := e.match
+	m.prev.next = m
+	if m.next != nil {
+		m.next.prev = m
+	}
+	e.prev.next = e
+	e.next.prev = e
+}
+
+// bitset records which operations have been linearized.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) bitset {
+	b[i/64] |= 1 << uint(i%64)
+	return b
+}
+
+func (b bitset) clear(i int) bitset {
+	b[i/64] &^= 1 << uint(i%64)
+	return b
+}
+
+func (b bitset) clone() bitset {
+	return append(bitset(nil), b...)
+}
+
+func (b bitset) equal(o bitset) bool {
+	for i := range b {
+		if b[i] != o[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b bitset) hash() uint64 {
+	h := uint64(14695981039346656037)
+	for _, w := range b {
+		h ^= w
+		h *= 1099511628211
+	}
+	return h
+}
+
+// cacheEntry is a visited combination of linearized operations and model state.
+type cacheEntry[S any] struct {
+	linearized bitset
+	state      S
+}
+
+// frame is a linearization step the search can backtrack over.
+type frame[S, I, O any] struct {
+	entry *entry[I, O]
+	state S
+}
+
+// checkSingle searches for a linearization of a single partition.
+func checkSingle[S, I, O any](model Model[S, I, O], history []Operation[I, O]) bool {
+	head := makeEntries(history)
+	state := model.Init()
+	linearized := newBitset(len(history))
+	cache := make(map[uint64][]cacheEntry[S])
+	var calls []frame[S, I, O]
+
+	seen := func(b bitset, s S) bool {
+		for _, c := range cache[b.hash()] {
+			if c.linearized.equal(b) && model.Equal(c.state, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	e := head.next
+	for head.next != nil {
+		if e.isCall {
+			ok, next := model.Step(state, e.input, e.match.output)
+			if ok {
+				candidate := linearized.clone().set(e.id)
+				if !seen(candidate, next) {
+					h := candidate.hash()
+					cache[h] = append(cache[h], cacheEntry[S]{linearized: candidate, state: next})
+					calls = append(calls, frame[S, I, O]{entry: e, state: state})
+					state = next
+					linearized.set(e.id)
+					lift(e)
+					e = head.next
+					continue
+				}
+			}
+			e = e.next
+			continue
+		}
+		// A return was reached before its call could be linearized: backtrack.
+		if len(calls) == 0 {
+			return false
+		}
+		top := calls[len(calls)-1]
+		calls = calls[:len(calls)-1]
+		state = top.state
+		linearized.clear(top.entry.id)
+		unlift(top.entry)
+		e = top.entry.next
+	}
+	return true
+}
+
+// Recorder collects the operations of concurrent clients into a history.
+// It is safe for concurrent use.
+type Recorder[I, O any] struct {
+	mu    sync.Mutex
+	start time.Time
+	ops   []Operation[I, O]
+}
+
+// NewRecorder creates a recorder whose clock starts now.
+// Example:
+//
+//	rec := linearizability.NewRecorder[queueInput, queueOutput]()
+func NewRecorder[I, O any]() *Recorder[I, O] {
+	return &Recorder[I, O]{start: time.Now()}
+}
+
+// Record runs fn as the operation input of client and records its output
+// with the times just before the call and just after the return.
+// Example:
+//
+//	rec.Record(client, queueInput{enqueue: true, value: 1}, func() queueOutput {
+//		q.Enqueue(1)
+//		return queueOutput{}
+//	})
+func (r *Recorder[I, O]) Record(client int, input I, fn func() O) O {
+	call := int64(time.Since(r.start))
+	output := fn()
+	ret := int64(time.Since(r.start))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, Operation[I, O]{ClientID: client, Input: input, Output: output, Call: call, Return: ret})
+	return output
+}
+
+// History returns a copy of the operations recorded so far.
+// Example:
+//
+//	history := rec.History()
+func (r *Recorder[I, O]) History() []Operation[I, O] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Operation[I, O](nil), r.ops...)
+}
@@ -0,0 +1,362 @@
+package linearizability
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/hayageek/threadsafe"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	clients          = 4
+	opsPerClient     = 50
+	keysPerPartition = 4
+)
+
+// runClients runs fn concurrently for each client with its own random source.
+func runClients(fn func(client int, rng *rand.Rand)) {
+	var wg sync.WaitGroup
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(c) + 1))
+			for i := 0; i < opsPerClient; i++ {
+				fn(c, rng)
+			}
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Register: a single integer cell, used to test the checker itself.
+
+type regInput struct {
+	write bool
+	value int
+}
+
+var registerModel = Model[int, regInput, int]{
+	Init: func() int { return 0 },
+	Step: func(state int, in regInput, out int) (bool, int) {
+		if in.write {
+			return true, in.value
+		}
+		return out == state, state
+	},
+}
+
+func TestCheckRegister(t *testing.T) {
+	write := Operation[regInput, int]{ClientID: 0, Input: regInput{write: true, value: 1}, Call: 0, Return: 10}
+
+	concurrentRead := Operation[regInput, int]{ClientID: 1, Output: 0, Call: 5, Return: 15}
+	assert.True(t, Check(registerModel, []Operation[regInput, int]{write, concurrentRead}))
+
+	laterRead := Operation[regInput, int]{ClientID: 1, Output: 1, Call: 20, Return: 30}
+	assert.True(t, Check(registerModel, []Operation[regInput, int]{write, laterRead}))
+
+	staleRead := Operation[regInput, int]{ClientID: 1, Output: 0, Call: 20, Return: 30}
+	assert.False(t, Check(registerModel, []Operation[regInput, int]{write, staleRead}))
+}
+
+func TestCheckBacktracks(t *testing.T) {
+	// Both writes overlap both reads; only write 1, read 1, write 2, read 2 works.
+	history := []Operation[regInput, int]{
+		{ClientID: 0, Input: regInput{write: true, value: 1}, Call: 0, Return: 100},
+		{ClientID: 1, Input: regInput{write: true, value: 2}, Call: 0, Return: 100},
+		{ClientID: 2, Output: 1, Call: 0, Return: 100},
+		{ClientID: 3, Output: 2, Call: 0, Return: 100},
+		{ClientID: 3, Output: 2, Call: 101, Return: 102},
+	}
+	assert.True(t, Check(registerModel, history))
+
+	history[4].Output = 1
+	history = append(history, Operation[regInput, int]{ClientID: 2, Output: 2, Call: 103, Return: 104})
+	assert.False(t, Check(registerModel, history))
+}
+
+// Key-value map, partitioned by key.
+
+type mapInput struct {
+	op    int // 0 Get, 1 Set, 2 Delete
+	key   int
+	value int
+}
+
+type mapOutput struct {
+	value int
+	ok    bool
+}
+
+type mapState struct {
+	value   int
+	present bool
+}
+
+var mapModel = Model[mapState, mapInput, mapOutput]{
+	Partition: func(history []Operation[mapInput, mapOutput]) [][]Operation[mapInput, mapOutput] {
+		byKey := make(map[int][]Operation[mapInput, mapOutput])
+		for _, op := range history {
+			byKey[op.Input.key] = append(byKey[op.Input.key], op)
+		}
+		var partitions [][]Operation[mapInput, mapOutput]
+		for _, ops := range byKey {
+			partitions = append(partitions, ops)
+		}
+		return partitions
+	},
+	Init: func() mapState { return mapState{} },
+	Step: func(state mapState, in mapInput, out mapOutput) (bool, mapState) {
+		switch in.op {
+		case 0:
+			return out.ok == state.present && (!out.ok || out.value == state.value), state
+		case 1:
+			return true, mapState{value: in.value, present: true}
+		default:
+			return true, mapState{}
+		}
+	},
+}
+
+// mapLike is the part of the map types exercised by the map tests.
+type mapLike interface {
+	Get(key int) (int, bool)
+	Set(key int, value int)
+	Delete(key int)
+}
+
+func recordMap(m mapLike) []Operation[mapInput, mapOutput] {
+	rec := NewRecorder[mapInput, mapOutput]()
+	runClients(func(client int, rng *rand.Rand) {
+		in := mapInput{op: rng.Intn(3), key: rng.Intn(keysPerPartition), value: rng.Intn(1000)}
+		rec.Record(client, in, func() mapOutput {
+			switch in.op {
+			case 0:
+				v, ok := m.Get(in.key)
+				return mapOutput{value: v, ok: ok}
+			case 1:
+				m.Set(in.key, in.value)
+			default:
+				m.Delete(in.key)
+			}
+			return mapOutput{}
+		})
+	})
+	return rec.History()
+}
+
+func TestMapLinearizable(t *testing.T) {
+	assert.True(t, Check(mapModel, recordMap(threadsafe.NewMap[int, int]())))
+}
+
+func TestCOWMapLinearizable(t *testing.T) {
+	assert.True(t, Check(mapModel, recordMap(threadsafe.NewCOWMap[int, int]())))
+}
+
+func TestSortedMapLinearizable(t *testing.T) {
+	assert.True(t, Check(mapModel, recordMap(threadsafe.NewSortedMap[int, int]())))
+}
+
+func TestSkipListLinearizable(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		assert.True(t, Check(mapModel, recordMap(threadsafe.NewSkipList[int, int]())))
+	}
+}
+
+func TestArrayLinearizable(t *testing.T) {
+	a := threadsafe.NewArray[int](keysPerPartition)
+	rec := NewRecorder[mapInput, mapOutput]()
+	runClients(func(client int, rng *rand.Rand) {
+		in := mapInput{op: rng.Intn(2), key: rng.Intn(keysPerPartition), value: rng.Intn(1000) + 1}
+		rec.Record(client, in, func() mapOutput {
+			if in.op == 0 {
+				v, _ := a.Get(in.key)
+				return mapOutput{value: v, ok: v != 0}
+			}
+			a.Set(in.key, in.value)
+			return mapOutput{}
+		})
+	})
+	assert.True(t, Check(mapModel, rec.History()))
+}
+
+// FIFO queue and LIFO stack of random values.
+
+type listInput struct {
+	add   bool
+	value int
+}
+
+type listOutput struct {
+	value int
+	ok    bool
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func listModel(lifo bool) Model[[]int, listInput, listOutput] {
+	return Model[[]int, listInput, listOutput]{
+		Init: func() []int { return nil },
+		Step: func(state []int, in listInput, out listOutput) (bool, []int) {
+			if in.add {
+				next := make([]int, len(state), len(state)+1)
+				copy(next, state)
+				return true, append(next, in.value)
+			}
+			if len(state) == 0 {
+				return !out.ok, state
+			}
+			if lifo {
+				return out.ok && out.value == state[len(state)-1], state[:len(state)-1]
+			}
+			return out.ok && out.value == state[0], state[1:]
+		},
+		Equal: equalInts,
+	}
+}
+
+func recordList(add func(int), remove func() (interface{}, bool)) []Operation[listInput, listOutput] {
+	rec := NewRecorder[listInput, listOutput]()
+	runClients(func(client int, rng *rand.Rand) {
+		in := listInput{add: rng.Intn(2) == 0, value: rng.Intn(1 << 30)}
+		rec.Record(client, in, func() listOutput {
+			if in.add {
+				add(in.value)
+				return listOutput{}
+			}
+			v, ok := remove()
+			if !ok {
+				return listOutput{}
+			}
+			return listOutput{value: v.(int), ok: true}
+		})
+	})
+	return rec.History()
+}
+
+func TestQueueLinearizable(t *testing.T) {
+	q := threadsafe.NewQueue()
+	history := recordList(func(v int) { q.Enqueue(v) }, q.Dequeue)
+	assert.True(t, Check(listModel(false), history))
+}
+
+func TestStackLinearizable(t *testing.T) {
+	s := threadsafe.NewStack()
+	history := recordList(func(v int) { s.Push(v) }, s.Pop)
+	assert.True(t, Check(listModel(true), history))
+}
+
+func TestQueueModelRejectsReordering(t *testing.T) {
+	history := []Operation[listInput, listOutput]{
+		{ClientID: 0, Input: listInput{add: true, value: 1}, Call: 0, Return: 1},
+		{ClientID: 0, Input: listInput{add: true, value: 2}, Call: 2, Return: 3},
+		{ClientID: 1, Output: listOutput{value: 2, ok: true}, Call: 4, Return: 5},
+	}
+	assert.False(t, Check(listModel(false), history))
+	assert.True(t, Check(listModel(true), history))
+}
+
+// Append-only slice with indexed reads and length.
+
+type sliceInput struct {
+	op    int // 0 Append, 1 Get, 2 Length
+	index int
+	value int
+}
+
+type sliceOutput struct {
+	value int
+	ok    bool
+	n     int
+}
+
+var sliceModel = Model[[]int, sliceInput, sliceOutput]{
+	Init: func() []int { return nil },
+	Step: func(state []int, in sliceInput, out sliceOutput) (bool, []int) {
+		switch in.op {
+		case 0:
+			next := make([]int, len(state), len(state)+1)
+			copy(next, state)
+			return true, append(next, in.value)
+		case 1:
+			if in.index >= len(state) {
+				return !out.ok, state
+			}
+			return out.ok && out.value == state[in.index], state
+		default:
+			return out.n == len(state), state
+		}
+	},
+	Equal: equalInts,
+}
+
+func TestSliceLinearizable(t *testing.T) {
+	s := threadsafe.NewSlice[int]()
+	rec := NewRecorder[sliceInput, sliceOutput]()
+	runClients(func(client int, rng *rand.Rand) {
+		in := sliceInput{op: rng.Intn(3), index: rng.Intn(opsPerClient), value: rng.Intn(1000)}
+		rec.Record(client, in, func() sliceOutput {
+			switch in.op {
+			case 0:
+				s.Append(in.value)
+			case 1:
+				v, ok := s.Get(in.index)
+				return sliceOutput{value: v, ok: ok}
+			default:
+				return sliceOutput{n: s.Length()}
+			}
+			return sliceOutput{}
+		})
+	})
+	assert.True(t, Check(sliceModel, rec.History()))
+}
+
+// brokenMap returns stale values from a cache that is never invalidated.
+type brokenMap struct {
+	m     *threadsafe.Map[int, int]
+	stale *threadsafe.Map[int, int]
+}
+
+func (b brokenMap) Get(key int) (int, bool) {
+	if v, ok := b.stale.Get(key); ok {
+		return v, true
+	}
+	v, ok := b.m.Get(key)
+	if ok {
+		b.stale.Set(key, v)
+	}
+	return v, ok
+}
+
+func (b brokenMap) Set(key int, value int) { b.m.Set(key, value) }
+func (b brokenMap) Delete(key int)         { b.m.Delete(key) }
+
+func TestCheckDetectsStaleReads(t *testing.T) {
+	b := brokenMap{m: threadsafe.NewMap[int, int](), stale: threadsafe.NewMap[int, int]()}
+	rec := NewRecorder[mapInput, mapOutput]()
+	for _, in := range []mapInput{{op: 1, value: 1}, {op: 0}, {op: 1, value: 2}, {op: 0}} {
+		rec.Record(0, in, func() mapOutput {
+			switch in.op {
+			case 0:
+				v, ok := b.Get(in.key)
+				return mapOutput{value: v, ok: ok}
+			default:
+				b.Set(in.key, in.value)
+				return mapOutput{}
+			}
+		})
+	}
+	assert.False(t, Check(mapModel, rec.History()))
+}
@@ -0,0 +1,100 @@
+package threadsafe
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// escapeCheck is non-zero when WithLock and WithRLock run in debug mode.
+var escapeCheck int32
+
+// SetEscapeCheck enables or disables escape detection for WithLock and WithRLock.
+// When enabled, callbacks receive a private copy of the collection's storage.
+// Once the callback returns, the copy is wiped and remembered; if it is later
+// found modified, the next WithLock or WithRLock call on the same collection
+// panics. Detection is best-effort and meant for tests and debugging only.
+// Example:
+//
+//	threadsafe.SetEscapeCheck(true)
+func SetEscapeCheck(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&escapeCheck, v)
+}
+
+func escapeCheckEnabled() bool {
+	return atomic.LoadInt32(&escapeCheck) != 0
+}
+
+// escapeGuard tracks storage lent to callbacks in escape-check mode.
+type escapeGuard struct {
+	mu      sync.Mutex
+	pending []func() bool
+}
+
+// track records a check that reports whether lent storage is still wiped.
+func (g *escapeGuard) track(wiped func() bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending = append(g.pending, wiped)
+}
+
+// verify panics if any previously lent storage was modified after its callback returned.
+func (g *escapeGuard) verify(method string) {
+	g.mu.Lock()
+	pending := g.pending
+	g.pending = nil
+	g.mu.Unlock()
+	for _, wiped := range pending {
+		if !wiped() {
+			panic(fmt.Sprintf("threadsafe: storage escaped a %s callback and was modified after it returned", method))
+		}
+	}
+}
+
+// lendSlice returns a private copy of data for a callback.
+func lendSlice[T any](data []T) []T {
+	lent := make([]T, len(data))
+	copy(lent, data)
+	return lent
+}
+
+// retireSlice wipes the lent slice and registers it with the guard.
+func retireSlice[T any](g *escapeGuard, lent []T) {
+	lent = lent[:cap(lent)]
+	var zero T
+	for i := range lent {
+		lent[i] = zero
+	}
+	g.track(func() bool {
+		for i := range lent {
+			if !reflect.ValueOf(&lent[i]).Elem().IsZero() {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// lendMap returns a private copy of data for a callback.
+func lendMap[K comparable, V any](data map[K]V) map[K]V {
+	lent := make(map[K]V, len(data))
+	for key, value := range data {
+		lent[key] = value
+	}
+	return lent
+}
+
+// retireMap empties the lent map and registers it with the guard.
+func retireMap[K comparable, V any](g *escapeGuard, lent map[K]V) {
+	for key := range lent {
+		delete(lent, key)
+	}
+	g.track(func() bool {
+		return len(lent) == 0
+	})
+}
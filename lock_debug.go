@@ -0,0 +1,221 @@
+package threadsafe
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LockDebugOptions configures lock debugging.
+// The zero value detects re-entrant locking and logs nothing.
+type LockDebugOptions struct {
+	// HoldThreshold is the duration after which a held lock is reported,
+	// once while it is still held and otherwise when it is released.
+	// Zero disables the reports.
+	HoldThreshold time.Duration
+	// Logf receives the reports. Defaults to log.Printf.
+	Logf func(format string, args ...interface{})
+}
+
+// lockDebug holds the active debug options, or nil when debugging is off.
+var lockDebug atomic.Pointer[LockDebugOptions]
+
+// EnableLockDebug makes collection locks track their owners by goroutine.
+// A goroutine that tries to lock a collection it already holds, for example
+// from inside a WithLock callback or an Atomically block, panics with its own
+// stack and the stack that acquired the lock, instead of deadlocking.
+// Locks held longer than opts.HoldThreshold are logged by a watchdog while
+// they are still held, so that locks that are never released are reported too.
+// Debugging captures a stack trace on every lock and is meant for tests only.
+// Example:
+//
+//	threadsafe.EnableLockDebug(threadsafe.LockDebugOptions{HoldThreshold: 100 * time.Millisecond})
+//	defer threadsafe.DisableLockDebug()
+func EnableLockDebug(opts LockDebugOptions) {
+	if opts.Logf == nil {
+		opts.Logf = log.Printf
+	}
+	lockDebug.Store(&opts)
+	startWatchdog(&opts)
+}
+
+// DisableLockDebug turns lock debugging off.
+// Example:
+//
+//	threadsafe.DisableLockDebug()
+func DisableLockDebug() {
+	lockDebug.Store(nil)
+	startWatchdog(nil)
+}
+
+// heldLocks is the set of lockOwners that currently have holders.
+var heldLocks sync.Map // *lockOwners -> struct{}
+
+// watchdog is the goroutine reporting locks held past the threshold.
+var watchdog struct {
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+// startWatchdog stops the running watchdog, if any, and starts one for opts
+// unless opts is nil or has no hold threshold.
+func startWatchdog(opts *LockDebugOptions) {
+	watchdog.mu.Lock()
+	defer watchdog.mu.Unlock()
+	if watchdog.stop != nil {
+		close(watchdog.stop)
+		watchdog.stop = nil
+	}
+	if opts == nil || opts.HoldThreshold <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	watchdog.stop = stop
+	go runWatchdog(opts, stop)
+}
+
+// runWatchdog checks held locks twice per threshold until stop is closed.
+func runWatchdog(opts *LockDebugOptions, stop <-chan struct{}) {
+	interval := opts.HoldThreshold / 2
+	if interval <= 0 {
+		interval = opts.HoldThreshold
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			heldLocks.Range(func(key, _ interface{}) bool {
+				key.(*lockOwners).reportHeld(opts)
+				return true
+			})
+		}
+	}
+}
+
+// lockHolder is a goroutine holding a lock in debug mode.
+type lockHolder struct {
+	gid       uint64
+	exclusive bool
+	since     time.Time
+	stack     []byte
+	// reported is set once the watchdog has logged the hold.
+	reported bool
+}
+
+// lockOwners records the goroutines holding a lock while debugging is on.
+type lockOwners struct {
+	mu      sync.Mutex
+	holders []lockHolder
+	n       int32
+}
+
+// acquire panics if the calling goroutine already holds the lock.
+// It returns the caller's goroutine ID and stack for acquired.
+func (o *lockOwners) acquire() (uint64, []byte) {
+	stack := currentStack()
+	gid := goroutineID(stack)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, h := range o.holders {
+		if h.gid == gid {
+			mode := "shared"
+			if h.exclusive {
+				mode = "exclusive"
+			}
+			panic(fmt.Sprintf("threadsafe: goroutine %d tried to lock a collection it already holds (%s); this would deadlock\n\ncurrent stack:\n%s\nlock acquired at:\n%s", gid, mode, stack, h.stack))
+		}
+	}
+	return gid, stack
+}
+
+// acquired records the calling goroutine as a holder.
+func (o *lockOwners) acquired(gid uint64, stack []byte, exclusive bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.holders = append(o.holders, lockHolder{gid: gid, exclusive: exclusive, since: time.Now(), stack: stack})
+	atomic.AddInt32(&o.n, 1)
+	if len(o.holders) == 1 {
+		heldLocks.Store(o, struct{}{})
+	}
+}
+
+// reportHeld logs the holders that have held the lock longer than the
+// threshold and have not been reported yet.
+func (o *lockOwners) reportHeld(opts *LockDebugOptions) {
+	var held []lockHolder
+	o.mu.Lock()
+	for i := range o.holders {
+		h := &o.holders[i]
+		if !h.reported && time.Since(h.since) > opts.HoldThreshold {
+			h.reported = true
+			held = append(held, *h)
+		}
+	}
+	o.mu.Unlock()
+	for _, h := range held {
+		opts.Logf("threadsafe: lock held for %v and not yet released (threshold %v) by goroutine %d, acquired at:\n%s", time.Since(h.since), opts.HoldThreshold, h.gid, h.stack)
+	}
+}
+
+// release forgets the calling goroutine's hold and reports it if it exceeded
+// the threshold and the watchdog has not reported it already. A lock released by another goroutine than the one that took
+// it drops a holder of the same mode instead.
+func (o *lockOwners) release(exclusive bool) {
+	if atomic.LoadInt32(&o.n) == 0 {
+		return
+	}
+	gid := goroutineID(currentStack())
+	o.mu.Lock()
+	i := -1
+	for j, h := range o.holders {
+		if h.exclusive == exclusive && (i < 0 || h.gid == gid) {
+			i = j
+		}
+	}
+	if i < 0 {
+		o.mu.Unlock()
+		return
+	}
+	h := o.holders[i]
+	o.holders = append(o.holders[:i], o.holders[i+1:]...)
+	atomic.AddInt32(&o.n, -1)
+	if len(o.holders) == 0 {
+		heldLocks.Delete(o)
+	}
+	o.mu.Unlock()
+	if d := lockDebug.Load(); d != nil && d.HoldThreshold > 0 && !h.reported {
+		if held := time.Since(h.since); held > d.HoldThreshold {
+			d.Logf("threadsafe: lock held for %v (threshold %v) by goroutine %d, acquired at:\n%s", held, d.HoldThreshold, h.gid, h.stack)
+		}
+	}
+}
+
+// currentStack returns the calling goroutine's stack trace.
+func currentStack() []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// goroutineID parses the goroutine ID from the "goroutine N [...]" header of a stack trace.
+func goroutineID(stack []byte) uint64 {
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i >= 0 {
+		stack = stack[:i]
+	}
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}
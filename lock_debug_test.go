@@ -0,0 +1,188 @@
+package threadsafe
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recoverMessage(fn func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	fn()
+	return ""
+}
+
+func TestLockDebugReentrantLock(t *testing.T) {
+	EnableLockDebug(LockDebugOptions{})
+	defer DisableLockDebug()
+
+	m := NewMap[string, int]()
+	msg := recoverMessage(func() {
+		m.WithLock(func(data map[string]int) {
+			m.Set("a", 1)
+		})
+	})
+	assert.Contains(t, msg, "already holds (exclusive)")
+	assert.Contains(t, msg, "current stack:")
+	assert.Contains(t, msg, "lock acquired at:")
+	parts := strings.SplitN(msg, "lock acquired at:", 2)
+	assert.Contains(t, parts[0], "TestLockDebugReentrantLock")
+	assert.Contains(t, parts[1], "TestLockDebugReentrantLock")
+
+	// The map is usable again once the panic unwound the callback.
+	m.Set("a", 1)
+	assert.Equal(t, 1, m.Length())
+}
+
+func TestLockDebugReentrantShared(t *testing.T) {
+	EnableLockDebug(LockDebugOptions{})
+	defer DisableLockDebug()
+
+	s := NewSlice[int]()
+	s.Append(1)
+	msg := recoverMessage(func() {
+		s.WithRLock(func(data []int) {
+			s.Get(0)
+		})
+	})
+	assert.Contains(t, msg, "already holds (shared)")
+}
+
+func TestLockDebugReentrantStats(t *testing.T) {
+	EnableLockDebug(LockDebugOptions{})
+	defer DisableLockDebug()
+
+	m := NewMap[string, int]()
+	m.EnableStats()
+	msg := recoverMessage(func() {
+		m.WithLock(func(data map[string]int) {
+			m.Stats()
+		})
+	})
+	assert.Contains(t, msg, "already holds (exclusive)")
+
+	q := NewQueue()
+	msg = recoverMessage(func() {
+		Atomically(func(tx *Txn) error {
+			q.Stats()
+			return nil
+		}, q)
+	})
+	assert.Contains(t, msg, "already holds (exclusive)")
+}
+
+func TestLockDebugAtomically(t *testing.T) {
+	EnableLockDebug(LockDebugOptions{})
+	defer DisableLockDebug()
+
+	q := NewQueue()
+	msg := recoverMessage(func() {
+		Atomically(func(tx *Txn) error {
+			q.Enqueue(1)
+			return nil
+		}, q)
+	})
+	assert.Contains(t, msg, "already holds (exclusive)")
+}
+
+func TestLockDebugConcurrentReaders(t *testing.T) {
+	EnableLockDebug(LockDebugOptions{})
+	defer DisableLockDebug()
+
+	m := NewMap[int, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Set(i, j)
+				m.Get(i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 8, m.Length())
+}
+
+func TestLockDebugHoldThreshold(t *testing.T) {
+	var mu sync.Mutex
+	var logs []string
+	EnableLockDebug(LockDebugOptions{
+		HoldThreshold: 10 * time.Millisecond,
+		Logf: func(format string, args ...interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	})
+	defer DisableLockDebug()
+
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	m.WithLock(func(data map[string]int) {
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, logs, 1)
+	assert.Contains(t, logs[0], "lock held for")
+	assert.Contains(t, logs[0], "TestLockDebugHoldThreshold")
+}
+
+func TestLockDebugDisabledWhileHeld(t *testing.T) {
+	EnableLockDebug(LockDebugOptions{})
+	m := NewMap[string, int]()
+	m.WithLock(func(data map[string]int) {
+		DisableLockDebug()
+	})
+	EnableLockDebug(LockDebugOptions{})
+	defer DisableLockDebug()
+
+	assert.NotPanics(t, func() { m.Set("a", 1) })
+}
+
+func TestLockDebugWatchdogReportsUnreleasedLock(t *testing.T) {
+	var mu sync.Mutex
+	var logs []string
+	EnableLockDebug(LockDebugOptions{
+		HoldThreshold: 10 * time.Millisecond,
+		Logf: func(format string, args ...interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	})
+	defer DisableLockDebug()
+
+	m := NewMap[string, int]()
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.WithLock(func(data map[string]int) { <-release })
+	}()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(logs) > 0
+	}, time.Second, time.Millisecond)
+	close(release)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, logs, 1)
+	assert.Contains(t, logs[0], "not yet released")
+	assert.Contains(t, logs[0], "TestLockDebugWatchdogReportsUnreleasedLock")
+}
@@ -1,14 +1,13 @@
 package threadsafe
 
-import (
-	"sync"
-)
-
 // Map represents a thread-safe map.
 // It uses a mutex to ensure that all operations are thread-safe.
 type Map[K comparable, V any] struct {
-	data map[K]V
-	mu   sync.RWMutex
+	data  map[K]V
+	mu    rwMutex
+	esc   escapeGuard
+	id    lockID
+	watch eventHub[MapEvent[K, V]]
 }
 
 // NewMap creates a new thread-safe map.
@@ -25,6 +24,7 @@ func NewMap[K comparable, V any]() *Map[K, V] {
 //
 //	value, ok := m.Get("key")
 func (m *Map[K, V]) Get(key K) (V, bool) {
+	m.mu.count("Get")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	value, exists := m.data[key]
@@ -36,9 +36,15 @@ func (m *Map[K, V]) Get(key K) (V, bool) {
 //
 //	m.Set("key", 100)
 func (m *Map[K, V]) Set(key K, value V) {
+	m.mu.count("Set")
+	var events []MapEvent[K, V]
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	defer func() { m.unlockAndNotify(events) }()
+	old, existed := m.data[key]
 	m.data[key] = value
+	if m.watch.active() {
+		events = []MapEvent[K, V]{{Type: MapEventSet, Key: key, OldValue: old, NewValue: value, Existed: existed}}
+	}
 }
 
 // Delete removes the value associated with the key.
@@ -46,9 +52,15 @@ func (m *Map[K, V]) Set(key K, value V) {
 //
 //	m.Delete("key")
 func (m *Map[K, V]) Delete(key K) {
+	m.mu.count("Delete")
+	var events []MapEvent[K, V]
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	defer func() { m.unlockAndNotify(events) }()
+	old, existed := m.data[key]
 	delete(m.data, key)
+	if existed && m.watch.active() {
+		events = []MapEvent[K, V]{{Type: MapEventDelete, Key: key, OldValue: old, Existed: true}}
+	}
 }
 
 // Length returns the number of key-value pairs in the map.
@@ -56,6 +68,7 @@ func (m *Map[K, V]) Delete(key K) {
 //
 //	length := m.Length()
 func (m *Map[K, V]) Length() int {
+	m.mu.count("Length")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.data)
@@ -66,6 +79,7 @@ func (m *Map[K, V]) Length() int {
 //
 //	keys := m.Keys()
 func (m *Map[K, V]) Keys() []K {
+	m.mu.count("Keys")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	keys := make([]K, 0, len(m.data))
@@ -80,6 +94,7 @@ func (m *Map[K, V]) Keys() []K {
 //
 //	values := m.Values()
 func (m *Map[K, V]) Values() []V {
+	m.mu.count("Values")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	values := make([]V, 0, len(m.data))
@@ -94,6 +109,7 @@ func (m *Map[K, V]) Values() []V {
 //
 //	contains := m.Contains("key")
 func (m *Map[K, V]) Contains(key K) bool {
+	m.mu.count("Contains")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	_, exists := m.data[key]
@@ -105,9 +121,14 @@ func (m *Map[K, V]) Contains(key K) bool {
 //
 //	m.Clear()
 func (m *Map[K, V]) Clear() {
+	m.mu.count("Clear")
+	var events []MapEvent[K, V]
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	defer func() { m.unlockAndNotify(events) }()
 	m.data = make(map[K]V)
+	if m.watch.active() {
+		events = []MapEvent[K, V]{{Type: MapEventClear}}
+	}
 }
 
 // Copy returns a new thread-safe map that is a copy of the current map.
@@ -115,6 +136,7 @@ func (m *Map[K, V]) Clear() {
 //
 //	copyMap := m.Copy()
 func (m *Map[K, V]) Copy() *Map[K, V] {
+	m.mu.count("Copy")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	dataCopy := make(map[K]V, len(m.data))
@@ -123,3 +145,49 @@ func (m *Map[K, V]) Copy() *Map[K, V] {
 	}
 	return &Map[K, V]{data: dataCopy}
 }
+
+// WithLock runs fn with exclusive access to the underlying map.
+// Changes fn makes to data are kept. fn must not retain data or call other
+// methods on the map.
+// Example:
+//
+//	m.WithLock(func(data map[string]int) {
+//		data["total"] = data["a"] + data["b"]
+//		delete(data, "a")
+//	})
+func (m *Map[K, V]) WithLock(fn func(data map[K]V)) {
+	m.mu.count("WithLock")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !escapeCheckEnabled() {
+		fn(m.data)
+		return
+	}
+	m.esc.verify("WithLock")
+	lent := lendMap(m.data)
+	fn(lent)
+	m.data = lendMap(lent)
+	retireMap(&m.esc, lent)
+}
+
+// WithRLock runs fn with shared, read-only access to the underlying map.
+// fn must not modify or retain data, or call methods that modify the map.
+// Example:
+//
+//	var sum int
+//	m.WithRLock(func(data map[string]int) {
+//		sum = data["a"] + data["b"]
+//	})
+func (m *Map[K, V]) WithRLock(fn func(data map[K]V)) {
+	m.mu.count("WithRLock")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !escapeCheckEnabled() {
+		fn(m.data)
+		return
+	}
+	m.esc.verify("WithRLock")
+	lent := lendMap(m.data)
+	fn(lent)
+	retireMap(&m.esc, lent)
+}
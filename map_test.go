@@ -85,3 +85,48 @@ func TestMapCopy(t *testing.T) {
 		assert.Equal(t, origValue, copyValue)
 	}
 }
+
+func TestMapWithLock(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.WithLock(func(data map[string]int) {
+		data["total"] = data["a"] + data["b"]
+		delete(data, "a")
+	})
+	value, ok := m.Get("total")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	assert.False(t, m.Contains("a"))
+}
+
+func TestMapWithRLock(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	var value int
+	m.WithRLock(func(data map[string]int) {
+		value = data["a"]
+	})
+	assert.Equal(t, 1, value)
+}
+
+func TestMapWithLockEscapeCheck(t *testing.T) {
+	SetEscapeCheck(true)
+	defer SetEscapeCheck(false)
+
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	var escaped map[string]int
+	m.WithLock(func(data map[string]int) {
+		data["b"] = 2
+		escaped = data
+	})
+	assert.Equal(t, 2, m.Length())
+	assert.Equal(t, 0, len(escaped))
+
+	escaped["c"] = 3
+	assert.Panics(t, func() {
+		m.WithLock(func(data map[string]int) {})
+	})
+	assert.False(t, m.Contains("c"))
+}
@@ -0,0 +1,126 @@
+package threadsafe
+
+// MapTx is a transaction over a Map, created by Map.Tx.
+// Writes are buffered and applied to the map only when the transaction commits.
+// A MapTx must not be used after the callback that received it returns.
+type MapTx[K comparable, V any] struct {
+	m       *Map[K, V]
+	changes map[K]txChange[V]
+	order   []K // keys in the order they were first written
+}
+
+// txChange is a buffered write or delete of a single key.
+type txChange[V any] struct {
+	value   V
+	deleted bool
+}
+
+// Tx runs fn as a transaction while holding the map's lock.
+// If fn returns nil, all changes made through tx are applied at once.
+// If fn returns an error or panics, the map is left unchanged.
+// Tx returns the error from fn.
+// Example:
+//
+//	err := m.Tx(func(tx *threadsafe.MapTx[string, int64]) error {
+//		from, _ := tx.Get("alice")
+//		if from < 10 {
+//			return errors.New("insufficient funds")
+//		}
+//		to, _ := tx.Get("bob")
+//		tx.Set("alice", from-10)
+//		tx.Set("bob", to+10)
+//		return nil
+//	})
+func (m *Map[K, V]) Tx(fn func(tx *MapTx[K, V]) error) error {
+	m.mu.count("Tx")
+	var events []MapEvent[K, V]
+	m.mu.Lock()
+	defer func() { m.unlockAndNotify(events) }()
+	tx := &MapTx[K, V]{m: m, changes: make(map[K]txChange[V])}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	events = tx.apply(m.watch.active())
+	return nil
+}
+
+// commit applies the buffered changes. The caller must hold the map's lock.
+func (tx *MapTx[K, V]) commit() {
+	tx.apply(false)
+}
+
+// rollback discards the buffered changes, which never reached the map.
+func (tx *MapTx[K, V]) rollback() {}
+
+// apply applies the buffered changes in the order their keys were first
+// written and, if record is set, returns the resulting events in that order.
+// The caller must hold the map's lock.
+func (tx *MapTx[K, V]) apply(record bool) []MapEvent[K, V] {
+	var events []MapEvent[K, V]
+	for _, key := range tx.order {
+		change := tx.changes[key]
+		old, existed := tx.m.data[key]
+		if change.deleted {
+			delete(tx.m.data, key)
+			if record && existed {
+				events = append(events, MapEvent[K, V]{Type: MapEventDelete, Key: key, OldValue: old, Existed: true})
+			}
+		} else {
+			tx.m.data[key] = change.value
+			if record {
+				events = append(events, MapEvent[K, V]{Type: MapEventSet, Key: key, OldValue: old, NewValue: change.value, Existed: existed})
+			}
+		}
+	}
+	return events
+}
+
+// Get retrieves the value associated with the key, including uncommitted changes.
+// It returns the value and a boolean indicating whether the key was found.
+// Example:
+//
+//	value, ok := tx.Get("key")
+func (tx *MapTx[K, V]) Get(key K) (V, bool) {
+	if change, ok := tx.changes[key]; ok {
+		if change.deleted {
+			var zero V
+			return zero, false
+		}
+		return change.value, true
+	}
+	value, exists := tx.m.data[key]
+	return value, exists
+}
+
+// Set sets the value for the given key when the transaction commits.
+// Example:
+//
+//	tx.Set("key", 100)
+func (tx *MapTx[K, V]) Set(key K, value V) {
+	tx.write(key, txChange[V]{value: value})
+}
+
+// Delete removes the key when the transaction commits.
+// Example:
+//
+//	tx.Delete("key")
+func (tx *MapTx[K, V]) Delete(key K) {
+	tx.write(key, txChange[V]{deleted: true})
+}
+
+// write buffers change for key, remembering the order of first writes.
+func (tx *MapTx[K, V]) write(key K, change txChange[V]) {
+	if _, ok := tx.changes[key]; !ok {
+		tx.order = append(tx.order, key)
+	}
+	tx.changes[key] = change
+}
+
+// Contains checks if the key is present, including uncommitted changes.
+// Example:
+//
+//	contains := tx.Contains("key")
+func (tx *MapTx[K, V]) Contains(key K) bool {
+	_, exists := tx.Get(key)
+	return exists
+}
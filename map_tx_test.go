@@ -0,0 +1,121 @@
+package threadsafe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func transfer(m *Map[string, int64], from, to string, amount int64) error {
+	return m.Tx(func(tx *MapTx[string, int64]) error {
+		balance, _ := tx.Get(from)
+		if balance < amount {
+			return errors.New("insufficient funds")
+		}
+		target, _ := tx.Get(to)
+		tx.Set(from, balance-amount)
+		tx.Set(to, target+amount)
+		return nil
+	})
+}
+
+func TestMapTxCommit(t *testing.T) {
+	m := NewMap[string, int64]()
+	m.Set("alice", 100)
+	err := transfer(m, "alice", "bob", 30)
+	assert.NoError(t, err)
+	alice, _ := m.Get("alice")
+	bob, _ := m.Get("bob")
+	assert.Equal(t, int64(70), alice)
+	assert.Equal(t, int64(30), bob)
+}
+
+func TestMapTxRollback(t *testing.T) {
+	m := NewMap[string, int64]()
+	m.Set("alice", 10)
+	err := m.Tx(func(tx *MapTx[string, int64]) error {
+		tx.Set("alice", 0)
+		tx.Delete("bob")
+		tx.Set("carol", 5)
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+	alice, _ := m.Get("alice")
+	assert.Equal(t, int64(10), alice)
+	assert.False(t, m.Contains("carol"))
+}
+
+func TestMapTxReadYourWrites(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	err := m.Tx(func(tx *MapTx[string, int]) error {
+		tx.Set("b", 2)
+		value, ok := tx.Get("b")
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+
+		tx.Delete("a")
+		assert.False(t, tx.Contains("a"))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, m.Contains("a"))
+	assert.True(t, m.Contains("b"))
+}
+
+func TestMapTxPanicLeavesMapUnchanged(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	assert.Panics(t, func() {
+		_ = m.Tx(func(tx *MapTx[string, int]) error {
+			tx.Set("a", 2)
+			panic("boom")
+		})
+	})
+	value, _ := m.Get("a")
+	assert.Equal(t, 1, value)
+}
+
+func TestMapTxConcurrentTransfers(t *testing.T) {
+	m := NewMap[string, int64]()
+	m.Set("a", 1000)
+	m.Set("b", 1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = transfer(m, "a", "b", 3)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = transfer(m, "b", "a", 5)
+		}()
+	}
+	wg.Wait()
+	a, _ := m.Get("a")
+	b, _ := m.Get("b")
+	assert.Equal(t, int64(2000), a+b)
+}
+
+func TestMapTxEventsInWriteOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[int, int]()
+	ch := m.Watch(ctx, WatchOptions{Buffer: 64})
+
+	keys := []int{9, 3, 7, 1, 5, 8, 2, 6, 4, 0}
+	assert.NoError(t, m.Tx(func(tx *MapTx[int, int]) error {
+		for _, k := range keys {
+			tx.Set(k, k)
+		}
+		tx.Set(9, 90) // rewriting a key keeps its first position
+		return nil
+	}))
+	for _, k := range keys {
+		assert.Equal(t, k, receiveEvent(t, ch).Key)
+	}
+}
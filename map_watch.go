@@ -0,0 +1,107 @@
+package threadsafe
+
+import "context"
+
+// MapEventType identifies the kind of change a MapEvent describes.
+type MapEventType int
+
+const (
+	// MapEventSet reports that a key was set.
+	MapEventSet MapEventType = iota + 1
+	// MapEventDelete reports that a key was removed.
+	MapEventDelete
+	// MapEventClear reports that all keys were removed.
+	MapEventClear
+)
+
+// MapEvent describes a change to a Map.
+// For MapEventClear, Key, OldValue and NewValue are zero.
+type MapEvent[K comparable, V any] struct {
+	Type MapEventType
+	Key  K
+	// OldValue is the previous value; it is only meaningful if Existed is true.
+	OldValue V
+	// NewValue is the value that was set; it is zero for deletes.
+	NewValue V
+	// Existed reports whether the key was present before the change.
+	Existed bool
+}
+
+// Watch returns a channel that receives every change made to the map by Set,
+// Delete, Clear and Tx. Decoding into the map with UnmarshalJSON,
+// UnmarshalBinary or GobDecode is reported as a MapEventClear followed by a
+// MapEventSet for every decoded key. Changes made through WithLock or
+// Atomically are not reported. The channel is closed once ctx is done.
+// opts controls buffering and what happens when the reader falls behind;
+// by default events that do not fit in a 16-event buffer are dropped.
+// Example:
+//
+//	for ev := range m.Watch(ctx) {
+//		fmt.Println(ev.Type, ev.Key, ev.NewValue)
+//	}
+func (m *Map[K, V]) Watch(ctx context.Context, opts ...WatchOptions) <-chan MapEvent[K, V] {
+	return m.watch.subscribeChan(ctx, nil, watchOptions(opts))
+}
+
+// WatchKey is like Watch but only reports changes to key, including Clear.
+// Example:
+//
+//	for ev := range m.WatchKey(ctx, "feature") {
+//		fmt.Println("feature is now", ev.NewValue)
+//	}
+func (m *Map[K, V]) WatchKey(ctx context.Context, key K, opts ...WatchOptions) <-chan MapEvent[K, V] {
+	return m.watch.subscribeChan(ctx, keyFilter[K, V](key), watchOptions(opts))
+}
+
+// OnChange registers fn to be called for every change reported by Watch and
+// returns a function that unregisters it. fn runs on a goroutine of its own,
+// outside the map's lock, after the change is made and usually after the
+// writer has returned; calls happen one at a time in the order of the changes.
+// opts controls buffering as for Watch: by default, changes made while 16
+// are already waiting for fn are dropped, so a slow fn never holds up writers.
+// fn may read the map and call the returned function, but must not modify the map.
+// Example:
+//
+//	stop := m.OnChange(func(ev threadsafe.MapEvent[string, int]) {
+//		log.Println("changed", ev.Key)
+//	})
+//	defer stop()
+func (m *Map[K, V]) OnChange(fn func(MapEvent[K, V]), opts ...WatchOptions) func() {
+	return m.watch.subscribeFunc(nil, fn, watchOptions(opts))
+}
+
+// keyFilter matches events affecting key.
+func keyFilter[K comparable, V any](key K) func(MapEvent[K, V]) bool {
+	return func(ev MapEvent[K, V]) bool {
+		return ev.Type == MapEventClear || ev.Key == key
+	}
+}
+
+// watchOptions returns the first of opts, or the zero value.
+func watchOptions(opts []WatchOptions) WatchOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return WatchOptions{}
+}
+
+// replace swaps in data as the map's contents, reporting it to watchers as a
+// Clear followed by a Set of every key.
+func (m *Map[K, V]) replace(data map[K]V) {
+	var events []MapEvent[K, V]
+	m.mu.Lock()
+	defer func() { m.unlockAndNotify(events) }()
+	m.data = data
+	if m.watch.active() {
+		events = make([]MapEvent[K, V], 0, len(data)+1)
+		events = append(events, MapEvent[K, V]{Type: MapEventClear})
+		for key, value := range data {
+			events = append(events, MapEvent[K, V]{Type: MapEventSet, Key: key, NewValue: value})
+		}
+	}
+}
+
+// unlockAndNotify releases m.mu and delivers events to watchers.
+func (m *Map[K, V]) unlockAndNotify(events []MapEvent[K, V]) {
+	m.watch.unlockAndPublish(m.mu.Unlock, events)
+}
@@ -0,0 +1,304 @@
+package threadsafe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveEvent[K comparable, V any](t *testing.T, ch <-chan MapEvent[K, V]) MapEvent[K, V] {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return MapEvent[K, V]{}
+	}
+}
+
+func TestMapWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[string, int]()
+	ch := m.Watch(ctx)
+
+	m.Set("a", 1)
+	m.Set("a", 2)
+	m.Delete("a")
+	m.Delete("missing")
+	m.Clear()
+
+	assert.Equal(t, MapEvent[string, int]{Type: MapEventSet, Key: "a", NewValue: 1}, receiveEvent(t, ch))
+	assert.Equal(t, MapEvent[string, int]{Type: MapEventSet, Key: "a", OldValue: 1, NewValue: 2, Existed: true}, receiveEvent(t, ch))
+	assert.Equal(t, MapEvent[string, int]{Type: MapEventDelete, Key: "a", OldValue: 2, Existed: true}, receiveEvent(t, ch))
+	assert.Equal(t, MapEventClear, receiveEvent(t, ch).Type)
+}
+
+func TestMapWatchClosesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewMap[string, int]()
+	ch := m.Watch(ctx)
+	cancel()
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed")
+	}
+	m.Set("a", 1)
+}
+
+func TestMapWatchKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[string, int]()
+	ch := m.WatchKey(ctx, "b")
+	m.Set("a", 1)
+	m.Set("b", 2)
+	ev := receiveEvent(t, ch)
+	assert.Equal(t, "b", ev.Key)
+	assert.Equal(t, 2, ev.NewValue)
+}
+
+func TestMapWatchTx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	ch := m.Watch(ctx)
+	_ = m.Tx(func(tx *MapTx[string, int]) error {
+		tx.Delete("a")
+		return nil
+	})
+	ev := receiveEvent(t, ch)
+	assert.Equal(t, MapEventDelete, ev.Type)
+	assert.Equal(t, 1, ev.OldValue)
+}
+
+func TestMapWatchOverflowDrop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[int, int]()
+	ch := m.Watch(ctx, WatchOptions{Buffer: 2})
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	assert.Equal(t, 0, receiveEvent(t, ch).Key)
+	assert.Equal(t, 1, receiveEvent(t, ch).Key)
+	select {
+	case ev := <-ch:
+		t.Fatalf("unexpected event %v", ev)
+	default:
+	}
+}
+
+func TestMapWatchOverflowBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[int, int]()
+	ch := m.Watch(ctx, WatchOptions{Buffer: 1, Overflow: OverflowBuffer})
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, i, receiveEvent(t, ch).Key)
+	}
+}
+
+func TestMapWatchOverflowBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewMap[int, int]()
+	m.Watch(ctx, WatchOptions{Buffer: 1, Overflow: OverflowBlock})
+	m.Set(1, 1)
+
+	done := make(chan struct{})
+	go func() {
+		m.Set(2, 2)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("writer did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Readers are not stalled by a blocked subscriber.
+	assert.True(t, m.Contains(2))
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writer still blocked after cancel")
+	}
+}
+
+func TestMapOnChange(t *testing.T) {
+	m := NewMap[string, int]()
+	var mu sync.Mutex
+	var keys []string
+	stop := m.OnChange(func(ev MapEvent[string, int]) {
+		// Reading the map from a callback must not deadlock.
+		m.Get(ev.Key)
+		mu.Lock()
+		keys = append(keys, ev.Key)
+		mu.Unlock()
+	})
+	m.Set("a", 1)
+	m.Set("b", 2)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(keys) == 2
+	}, time.Second, time.Millisecond)
+	stop()
+	m.Set("c", 3)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestMapOnChangeOrder(t *testing.T) {
+	m := NewMap[string, int]()
+	var mu sync.Mutex
+	var last int
+	var ordered = true
+	m.OnChange(func(ev MapEvent[string, int]) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ev.Existed && ev.OldValue != last {
+			ordered = false
+		}
+		last = ev.NewValue
+	}, WatchOptions{Overflow: OverflowBlock})
+	var wg sync.WaitGroup
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Set("k", i)
+		}(i)
+	}
+	wg.Wait()
+	value, _ := m.Get("k")
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return last == value
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, ordered)
+}
+
+// waitOrFail fails the test if fn does not return within a few seconds.
+func waitOrFail(t *testing.T, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock: operation did not finish")
+	}
+}
+
+func TestMapOnChangeReadsUnderConcurrentWrites(t *testing.T) {
+	m := NewMap[int, int]()
+	m.OnChange(func(ev MapEvent[int, int]) {
+		m.Get(ev.Key)
+		m.Length()
+	})
+	waitOrFail(t, func() {
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					m.Set(g, i)
+				}
+			}(g)
+		}
+		wg.Wait()
+	})
+}
+
+func TestMapOnChangeStopFromCallback(t *testing.T) {
+	m := NewMap[string, int]()
+	var calls atomic.Int32
+	var stop func()
+	stop = m.OnChange(func(MapEvent[string, int]) {
+		calls.Add(1)
+		stop()
+	})
+	waitOrFail(t, func() {
+		m.Set("a", 1)
+		m.Set("b", 2)
+	})
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	m.Set("c", 3)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestMapSlowOnChangeDoesNotBlockReaders(t *testing.T) {
+	m := NewMap[string, int]()
+	release := make(chan struct{})
+	m.OnChange(func(MapEvent[string, int]) { <-release })
+	go m.Set("a", 1)
+	waitOrFail(t, func() {
+		for !m.Contains("a") {
+			time.Sleep(time.Millisecond)
+		}
+		m.Set("b", 2)
+		m.Get("a")
+	})
+	close(release)
+}
+
+func TestMapSlowOnChangeDoesNotDelayWriters(t *testing.T) {
+	m := NewMap[int, int]()
+	m.OnChange(func(MapEvent[int, int]) { time.Sleep(10 * time.Millisecond) })
+	var slowest atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				start := time.Now()
+				m.Set(g, i)
+				if d := int64(time.Since(start)); d > slowest.Load() {
+					slowest.Store(d)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	// Without a dispatcher of its own, a writer would deliver the backlog of
+	// roughly 1600 events at 10ms each.
+	assert.Less(t, time.Duration(slowest.Load()), 100*time.Millisecond)
+}
+
+func TestMapWatchDecode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMap[string, int]()
+	m.Set("old", 1)
+	ch := m.Watch(ctx)
+
+	assert.NoError(t, m.UnmarshalJSON([]byte(`{"a":1}`)))
+	assert.Equal(t, MapEventClear, receiveEvent(t, ch).Type)
+	assert.Equal(t, MapEvent[string, int]{Type: MapEventSet, Key: "a", NewValue: 1}, receiveEvent(t, ch))
+
+	b, err := NewMap[string, int]().MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, m.UnmarshalBinary(b))
+	assert.Equal(t, MapEventClear, receiveEvent(t, ch).Type)
+}
@@ -0,0 +1,214 @@
+// Package metrics exports the statistics of threadsafe collections through
+// expvar and the Prometheus text exposition format, without external dependencies.
+//
+// Example:
+//
+//	cache := threadsafe.NewMap[string, int]()
+//	metrics.Register("cache", cache)
+//	metrics.PublishExpvar("threadsafe")
+//	http.Handle("/metrics", metrics.Handler())
+package metrics
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hayageek/threadsafe"
+)
+
+// Source is a collection that reports statistics, such as *threadsafe.Map.
+type Source interface {
+	Stats() threadsafe.Stats
+}
+
+// statsEnabler is implemented by collections whose recording can be switched on.
+type statsEnabler interface {
+	EnableStats()
+}
+
+// Registry holds named collections whose statistics are exported.
+type Registry struct {
+	mu      sync.RWMutex
+	sources map[string]Source
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{sources: make(map[string]Source)}
+}
+
+// Default is the registry used by the package-level functions.
+var Default = NewRegistry()
+
+// Register adds a collection under name and enables its statistics.
+// It returns an error if name is already registered.
+// Example:
+//
+//	err := reg.Register("cache", cache)
+func (r *Registry) Register(name string, s Source) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.sources[name]; exists {
+		return fmt.Errorf("metrics: collection %q already registered", name)
+	}
+	if e, ok := s.(statsEnabler); ok {
+		e.EnableStats()
+	}
+	r.sources[name] = s
+	return nil
+}
+
+// Unregister removes the collection registered under name.
+// Example:
+//
+//	reg.Unregister("cache")
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sources, name)
+}
+
+// snapshot returns the statistics of every registered collection, sorted by name.
+func (r *Registry) snapshot() ([]string, map[string]threadsafe.Stats) {
+	r.mu.RLock()
+	sources := make(map[string]Source, len(r.sources))
+	for name, s := range r.sources {
+		sources[name] = s
+	}
+	r.mu.RUnlock()
+	names := make([]string, 0, len(sources))
+	stats := make(map[string]threadsafe.Stats, len(sources))
+	for name, s := range sources {
+		names = append(names, name)
+		stats[name] = s.Stats()
+	}
+	sort.Strings(names)
+	return names, stats
+}
+
+// expvarCollection is the expvar representation of one collection.
+type expvarCollection struct {
+	Size int               `json:"size"`
+	Ops  map[string]uint64 `json:"ops"`
+}
+
+// PublishExpvar publishes the registry's sizes and operation counts as the
+// expvar variable name. Like expvar.Publish, it panics if name is already in use.
+// Example:
+//
+//	reg.PublishExpvar("threadsafe")
+func (r *Registry) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		names, stats := r.snapshot()
+		out := make(map[string]expvarCollection, len(names))
+		for _, n := range names {
+			out[n] = expvarCollection{Size: stats[n].Size, Ops: stats[n].Ops}
+		}
+		return out
+	}))
+}
+
+// Handler returns an http.Handler that serves the registry's statistics in
+// the Prometheus text exposition format.
+// Example:
+//
+//	http.Handle("/metrics", reg.Handler())
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes the registry's statistics to w in the Prometheus
+// text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	names, stats := r.snapshot()
+	var b strings.Builder
+
+	writeHeader(&b, "threadsafe_collection_size", "gauge", "Current number of elements in the collection.")
+	for _, n := range names {
+		fmt.Fprintf(&b, "threadsafe_collection_size{collection=%s} %d\n", quote(n), stats[n].Size)
+	}
+
+	writeHeader(&b, "threadsafe_operations_total", "counter", "Number of calls per collection method.")
+	for _, n := range names {
+		ops := make([]string, 0, len(stats[n].Ops))
+		for op := range stats[n].Ops {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+		for _, op := range ops {
+			fmt.Fprintf(&b, "threadsafe_operations_total{collection=%s,op=%s} %d\n", quote(n), quote(op), stats[n].Ops[op])
+		}
+	}
+
+	writeHeader(&b, "threadsafe_lock_wait_seconds", "histogram", "Time spent waiting to acquire the collection lock.")
+	for _, n := range names {
+		if stats[n].Enabled {
+			writeHistogram(&b, "threadsafe_lock_wait_seconds", n, stats[n].LockWait)
+		}
+	}
+
+	writeHeader(&b, "threadsafe_lock_hold_seconds", "histogram", "Time the collection's exclusive lock was held.")
+	for _, n := range names {
+		if stats[n].Enabled {
+			writeHistogram(&b, "threadsafe_lock_hold_seconds", n, stats[n].LockHold)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHeader(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeHistogram writes h as cumulative Prometheus buckets. The +Inf bucket
+// and the count are derived from the buckets rather than h.Count, which is
+// recorded separately and may be out of step with them in a concurrent snapshot.
+func writeHistogram(b *strings.Builder, metric, collection string, h threadsafe.Histogram) {
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		fmt.Fprintf(b, "%s_bucket{collection=%s,le=\"%s\"} %d\n", metric, quote(collection), seconds(bound), cumulative)
+	}
+	for _, c := range h.Counts[len(h.Bounds):] {
+		cumulative += c
+	}
+	fmt.Fprintf(b, "%s_bucket{collection=%s,le=\"+Inf\"} %d\n", metric, quote(collection), cumulative)
+	fmt.Fprintf(b, "%s_sum{collection=%s} %s\n", metric, quote(collection), seconds(h.Sum))
+	fmt.Fprintf(b, "%s_count{collection=%s} %d\n", metric, quote(collection), cumulative)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// quote returns a Prometheus label value in double quotes.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// Register adds a collection to the Default registry.
+func Register(name string, s Source) error { return Default.Register(name, s) }
+
+// Unregister removes a collection from the Default registry.
+func Unregister(name string) { Default.Unregister(name) }
+
+// PublishExpvar publishes the Default registry as the expvar variable name.
+func PublishExpvar(name string) { Default.PublishExpvar(name) }
+
+// Handler serves the Default registry in the Prometheus text exposition format.
+func Handler() http.Handler { return Default.Handler() }
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hayageek/threadsafe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterDuplicate(t *testing.T) {
+	reg := NewRegistry()
+	assert.NoError(t, reg.Register("cache", threadsafe.NewMap[string, int]()))
+	assert.Error(t, reg.Register("cache", threadsafe.NewMap[string, int]()))
+	reg.Unregister("cache")
+	assert.NoError(t, reg.Register("cache", threadsafe.NewMap[string, int]()))
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	cache := threadsafe.NewMap[string, int]()
+	jobs := threadsafe.NewQueue()
+	assert.NoError(t, reg.Register("cache", cache))
+	assert.NoError(t, reg.Register(`jobs"q`, jobs))
+	cache.Set("a", 1)
+	cache.Get("a")
+	jobs.Enqueue(1)
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, out, "# TYPE threadsafe_collection_size gauge\n")
+	assert.Contains(t, out, `threadsafe_collection_size{collection="cache"} 1`+"\n")
+	assert.Contains(t, out, `threadsafe_collection_size{collection="jobs\"q"} 1`+"\n")
+	assert.Contains(t, out, `threadsafe_operations_total{collection="cache",op="Get"} 1`+"\n")
+	assert.Contains(t, out, `threadsafe_operations_total{collection="cache",op="Set"} 1`+"\n")
+	assert.Contains(t, out, `threadsafe_lock_wait_seconds_bucket{collection="cache",le="+Inf"} 2`+"\n")
+	assert.Contains(t, out, `threadsafe_lock_hold_seconds_count{collection="cache"} 1`+"\n")
+	assert.True(t, strings.Index(out, `collection="cache"`) < strings.Index(out, `collection="jobs\"q"`))
+}
+
+func TestPublishExpvar(t *testing.T) {
+	reg := NewRegistry()
+	slice := threadsafe.NewSlice[int]()
+	assert.NoError(t, reg.Register("listeners", slice))
+	slice.Append(1)
+	reg.PublishExpvar("threadsafe_test")
+
+	var out map[string]expvarCollection
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("threadsafe_test").String()), &out))
+	assert.Equal(t, 1, out["listeners"].Size)
+	assert.Equal(t, uint64(1), out["listeners"].Ops["Append"])
+}
+
+func TestWriteHistogramCountsFromBuckets(t *testing.T) {
+	var b strings.Builder
+	h := threadsafe.Histogram{
+		Bounds: []time.Duration{time.Millisecond},
+		Counts: []uint64{2, 3},
+		Count:  4, // behind the buckets, as in a snapshot taken mid-observation
+		Sum:    time.Second,
+	}
+	writeHistogram(&b, "m", "c", h)
+	out := b.String()
+	assert.Contains(t, out, `m_bucket{collection="c",le="0.001"} 2`+"\n")
+	assert.Contains(t, out, `m_bucket{collection="c",le="+Inf"} 5`+"\n")
+	assert.Contains(t, out, `m_count{collection="c"} 5`+"\n")
+}
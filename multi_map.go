@@ -0,0 +1,340 @@
+package threadsafe
+
+import "reflect"
+
+// MultiMap represents a thread-safe map from keys to lists of values.
+// Values under a key keep the order in which they were added and may repeat.
+// Use SetMultiMap when values under a key must be unique.
+type MultiMap[K comparable, V any] struct {
+	data  map[K][]V
+	count int
+	mu    rwMutex
+}
+
+// NewMultiMap creates a new thread-safe list-valued multimap.
+// Example:
+//
+//	m := threadsafe.NewMultiMap[string, Handler]()
+func NewMultiMap[K comparable, V any]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{data: make(map[K][]V)}
+}
+
+// Put appends value to the values of key.
+// Example:
+//
+//	m.Put("event", handler)
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	m.mu.count("Put")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = append(m.data[key], value)
+	m.count++
+}
+
+// GetAll returns a copy of the values of key in the order they were added.
+// It returns nil if the key is not present.
+// Example:
+//
+//	handlers := m.GetAll("event")
+func (m *MultiMap[K, V]) GetAll(key K) []V {
+	m.mu.count("GetAll")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	return append([]V(nil), values...)
+}
+
+// Contains checks if value is one of the values of key.
+// Values are compared with reflect.DeepEqual.
+// Example:
+//
+//	contains := m.Contains("tags", "go")
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	m.mu.count("Contains")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, v := range m.data[key] {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the first occurrence of value from the values of key.
+// Values are compared with reflect.DeepEqual, so functions can only be
+// removed with RemoveFunc. It returns a boolean indicating whether a value
+// was removed.
+// Example:
+//
+//	ok := m.Remove("tags", "go")
+func (m *MultiMap[K, V]) Remove(key K, value V) bool {
+	m.mu.count("Remove")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values := m.data[key]
+	for i, v := range values {
+		if reflect.DeepEqual(v, value) {
+			m.removeAt(key, values, i)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveFunc removes the values of key for which match returns true and
+// returns how many were removed.
+// Example:
+//
+//	n := m.RemoveFunc("event", func(h Handler) bool { return h.ID == id })
+func (m *MultiMap[K, V]) RemoveFunc(key K, match func(value V) bool) int {
+	m.mu.count("RemoveFunc")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values, ok := m.data[key]
+	if !ok {
+		return 0
+	}
+	kept := make([]V, 0, len(values))
+	for _, v := range values {
+		if !match(v) {
+			kept = append(kept, v)
+		}
+	}
+	removed := len(values) - len(kept)
+	m.count -= removed
+	if len(kept) == 0 {
+		delete(m.data, key)
+	} else {
+		m.data[key] = kept
+	}
+	return removed
+}
+
+// removeAt removes values[i] from the values of key. The caller must hold m.mu.
+func (m *MultiMap[K, V]) removeAt(key K, values []V, i int) {
+	m.count--
+	if len(values) == 1 {
+		delete(m.data, key)
+		return
+	}
+	kept := make([]V, 0, len(values)-1)
+	kept = append(kept, values[:i]...)
+	m.data[key] = append(kept, values[i+1:]...)
+}
+
+// RemoveAll removes key and all of its values.
+// Example:
+//
+//	m.RemoveAll("event")
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	m.mu.count("RemoveAll")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.count -= len(m.data[key])
+	delete(m.data, key)
+}
+
+// Keys returns the keys that have at least one value.
+// Example:
+//
+//	keys := m.Keys()
+func (m *MultiMap[K, V]) Keys() []K {
+	m.mu.count("Keys")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// KeyCount returns the number of keys that have at least one value.
+// Example:
+//
+//	keys := m.KeyCount()
+func (m *MultiMap[K, V]) KeyCount() int {
+	m.mu.count("KeyCount")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// ValueCount returns the number of values under all keys.
+// Example:
+//
+//	values := m.ValueCount()
+func (m *MultiMap[K, V]) ValueCount() int {
+	m.mu.count("ValueCount")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.count
+}
+
+// Clear removes all keys and values.
+// Example:
+//
+//	m.Clear()
+func (m *MultiMap[K, V]) Clear() {
+	m.mu.count("Clear")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[K][]V)
+	m.count = 0
+}
+
+// SetMultiMap represents a thread-safe map from keys to sets of values.
+// Adding a value that is already present under a key has no effect.
+type SetMultiMap[K, V comparable] struct {
+	data  map[K]map[V]struct{}
+	count int
+	mu    rwMutex
+}
+
+// NewSetMultiMap creates a new thread-safe set-valued multimap.
+// Example:
+//
+//	m := threadsafe.NewSetMultiMap[string, string]()
+func NewSetMultiMap[K, V comparable]() *SetMultiMap[K, V] {
+	return &SetMultiMap[K, V]{data: make(map[K]map[V]struct{})}
+}
+
+// Put adds value to the values of key.
+// It returns false if the value was already present.
+// Example:
+//
+//	added := m.Put("alice", "admin")
+func (m *SetMultiMap[K, V]) Put(key K, value V) bool {
+	m.mu.count("Put")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values, ok := m.data[key]
+	if !ok {
+		values = make(map[V]struct{})
+		m.data[key] = values
+	}
+	if _, exists := values[value]; exists {
+		return false
+	}
+	values[value] = struct{}{}
+	m.count++
+	return true
+}
+
+// GetAll returns the values of key in no particular order.
+// It returns nil if the key is not present.
+// Example:
+//
+//	roles := m.GetAll("alice")
+func (m *SetMultiMap[K, V]) GetAll(key K) []V {
+	m.mu.count("GetAll")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	set, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	values := make([]V, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	return values
+}
+
+// Contains checks if value is one of the values of key.
+// Example:
+//
+//	isAdmin := m.Contains("alice", "admin")
+func (m *SetMultiMap[K, V]) Contains(key K, value V) bool {
+	m.mu.count("Contains")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.data[key][value]
+	return exists
+}
+
+// Remove removes value from the values of key.
+// It returns a boolean indicating whether the value was present.
+// Example:
+//
+//	ok := m.Remove("alice", "admin")
+func (m *SetMultiMap[K, V]) Remove(key K, value V) bool {
+	m.mu.count("Remove")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values := m.data[key]
+	if _, exists := values[value]; !exists {
+		return false
+	}
+	delete(values, value)
+	if len(values) == 0 {
+		delete(m.data, key)
+	}
+	m.count--
+	return true
+}
+
+// RemoveAll removes key and all of its values.
+// Example:
+//
+//	m.RemoveAll("alice")
+func (m *SetMultiMap[K, V]) RemoveAll(key K) {
+	m.mu.count("RemoveAll")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.count -= len(m.data[key])
+	delete(m.data, key)
+}
+
+// Keys returns the keys that have at least one value.
+// Example:
+//
+//	keys := m.Keys()
+func (m *SetMultiMap[K, V]) Keys() []K {
+	m.mu.count("Keys")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// KeyCount returns the number of keys that have at least one value.
+// Example:
+//
+//	keys := m.KeyCount()
+func (m *SetMultiMap[K, V]) KeyCount() int {
+	m.mu.count("KeyCount")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// ValueCount returns the number of values under all keys.
+// Example:
+//
+//	values := m.ValueCount()
+func (m *SetMultiMap[K, V]) ValueCount() int {
+	m.mu.count("ValueCount")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.count
+}
+
+// Clear removes all keys and values.
+// Example:
+//
+//	m.Clear()
+func (m *SetMultiMap[K, V]) Clear() {
+	m.mu.count("Clear")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[K]map[V]struct{})
+	m.count = 0
+}
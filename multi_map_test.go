@@ -0,0 +1,145 @@
+package threadsafe
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMultiMap(t *testing.T) {
+	m := NewMultiMap[string, int]()
+	assert.Equal(t, 0, m.KeyCount())
+	assert.Equal(t, 0, m.ValueCount())
+	assert.Nil(t, m.GetAll("missing"))
+}
+
+func TestMultiMapPutGetAll(t *testing.T) {
+	m := NewMultiMap[string, int]()
+	m.Put("a", 1)
+	m.Put("a", 2)
+	m.Put("a", 1)
+	m.Put("b", 3)
+	assert.Equal(t, []int{1, 2, 1}, m.GetAll("a"))
+	assert.Equal(t, []int{3}, m.GetAll("b"))
+	assert.ElementsMatch(t, []string{"a", "b"}, m.Keys())
+	assert.Equal(t, 2, m.KeyCount())
+	assert.Equal(t, 4, m.ValueCount())
+	assert.True(t, m.Contains("a", 2))
+	assert.False(t, m.Contains("b", 2))
+
+	values := m.GetAll("a")
+	values[0] = 100
+	assert.Equal(t, []int{1, 2, 1}, m.GetAll("a"))
+}
+
+func TestMultiMapRemove(t *testing.T) {
+	m := NewMultiMap[string, int]()
+	m.Put("a", 1)
+	m.Put("a", 2)
+	m.Put("a", 1)
+	m.Put("b", 3)
+
+	assert.True(t, m.Remove("a", 1))
+	assert.Equal(t, []int{2, 1}, m.GetAll("a"))
+	assert.False(t, m.Remove("a", 5))
+	assert.False(t, m.Remove("missing", 1))
+	assert.True(t, m.Remove("b", 3))
+	assert.Nil(t, m.GetAll("b"))
+	assert.Equal(t, 1, m.KeyCount())
+	assert.Equal(t, 2, m.ValueCount())
+
+	m.RemoveAll("a")
+	assert.Equal(t, 0, m.KeyCount())
+	assert.Equal(t, 0, m.ValueCount())
+}
+
+func TestMultiMapRemoveFunc(t *testing.T) {
+	type handler struct {
+		id int
+		fn func()
+	}
+	m := NewMultiMap[string, handler]()
+	for i := 0; i < 4; i++ {
+		m.Put("event", handler{id: i, fn: func() {}})
+	}
+	assert.False(t, m.Remove("event", m.GetAll("event")[0]))
+
+	n := m.RemoveFunc("event", func(h handler) bool { return h.id%2 == 0 })
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, m.ValueCount())
+	assert.Equal(t, 0, m.RemoveFunc("missing", func(h handler) bool { return true }))
+	assert.Equal(t, 2, m.RemoveFunc("event", func(h handler) bool { return true }))
+	assert.Equal(t, 0, m.KeyCount())
+}
+
+func TestMultiMapClear(t *testing.T) {
+	m := NewMultiMap[string, int]()
+	m.Put("a", 1)
+	m.Clear()
+	assert.Equal(t, 0, m.KeyCount())
+	assert.Equal(t, 0, m.ValueCount())
+}
+
+func TestMultiMapConcurrentPut(t *testing.T) {
+	m := NewMultiMap[string, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				m.Put("event", g*100+i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Len(t, m.GetAll("event"), 800)
+	assert.Equal(t, 800, m.ValueCount())
+}
+
+func TestSetMultiMap(t *testing.T) {
+	m := NewSetMultiMap[string, string]()
+	assert.True(t, m.Put("alice", "admin"))
+	assert.True(t, m.Put("alice", "dev"))
+	assert.False(t, m.Put("alice", "admin"))
+	assert.True(t, m.Put("bob", "dev"))
+	assert.ElementsMatch(t, []string{"admin", "dev"}, m.GetAll("alice"))
+	assert.Nil(t, m.GetAll("carol"))
+	assert.ElementsMatch(t, []string{"alice", "bob"}, m.Keys())
+	assert.Equal(t, 2, m.KeyCount())
+	assert.Equal(t, 3, m.ValueCount())
+	assert.True(t, m.Contains("alice", "admin"))
+	assert.False(t, m.Contains("bob", "admin"))
+
+	assert.True(t, m.Remove("alice", "admin"))
+	assert.False(t, m.Remove("alice", "admin"))
+	assert.True(t, m.Remove("bob", "dev"))
+	assert.Equal(t, 1, m.KeyCount())
+	assert.Equal(t, 1, m.ValueCount())
+
+	m.RemoveAll("alice")
+	assert.Equal(t, 0, m.KeyCount())
+	assert.Equal(t, 0, m.ValueCount())
+
+	m.Put("alice", "admin")
+	m.Clear()
+	assert.Equal(t, 0, m.ValueCount())
+}
+
+func TestSetMultiMapConcurrentPut(t *testing.T) {
+	m := NewSetMultiMap[string, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				m.Put("event", i)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, m.GetAll("event"), 100)
+	assert.Equal(t, 100, m.ValueCount())
+}
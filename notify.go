@@ -0,0 +1,257 @@
+package threadsafe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens when a subscriber falls behind.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards events that do not fit in the subscriber's buffer.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock makes a writer whose events do not fit in the subscriber's
+	// buffer wait, after releasing the collection's lock, until the subscriber
+	// has caught up or is removed. Other goroutines can still use the collection
+	// meanwhile, and the subscriber's buffer exceeds its size by at most one
+	// write per waiting writer.
+	OverflowBlock
+	// OverflowBuffer queues events without limit until the subscriber takes them.
+	OverflowBuffer
+)
+
+// defaultWatchBuffer is the buffer size used when WatchOptions.Buffer is zero.
+const defaultWatchBuffer = 16
+
+// WatchOptions configures a subscription.
+// The zero value uses a buffer of 16 events and OverflowDrop.
+type WatchOptions struct {
+	// Buffer is the number of events a subscriber may have pending, including
+	// the one being delivered. Defaults to 16.
+	Buffer int
+	// Overflow decides what happens when the buffer is full.
+	Overflow OverflowPolicy
+}
+
+// eventSink is a single subscriber with its own queue of pending events.
+// Writers append to the queue, applying the overflow policy, and a dispatcher
+// goroutine, started when the queue becomes non-empty and exiting when it
+// drains, delivers the events in order by calling fn or sending on ch.
+type eventSink[E any] struct {
+	id     uint64
+	filter func(E) bool
+	fn     func(E)
+	ch     chan E // unbuffered; queue is the buffer
+	size   int
+	policy OverflowPolicy
+	quit   chan struct{} // closed by remove
+
+	mu      sync.Mutex // guards the fields below
+	room    sync.Cond  // signalled when the queue shrinks or the sink is removed
+	queue   []E        // queue[0] is being delivered while running
+	running bool
+	removed bool
+}
+
+// newEventSink returns a sink that delivers to fn, or to a new channel if fn is nil.
+func newEventSink[E any](filter func(E) bool, fn func(E), opts WatchOptions) *eventSink[E] {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultWatchBuffer
+	}
+	s := &eventSink[E]{filter: filter, fn: fn, size: opts.Buffer, policy: opts.Overflow, quit: make(chan struct{})}
+	s.room.L = &s.mu
+	if fn == nil {
+		s.ch = make(chan E)
+	}
+	return s
+}
+
+// enqueue adds the events s subscribes to from events to its queue,
+// dropping those that do not fit under OverflowDrop. It reports whether
+// the queue is over its size, in which case an OverflowBlock writer must
+// call waitRoom once it has released the collection's lock.
+func (s *eventSink[E]) enqueue(events []E) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.removed {
+		return false
+	}
+	for _, e := range events {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		if s.policy == OverflowDrop && len(s.queue) >= s.size {
+			continue
+		}
+		s.queue = append(s.queue, e)
+	}
+	if len(s.queue) > 0 && !s.running {
+		s.running = true
+		go s.run()
+	}
+	return s.policy == OverflowBlock && len(s.queue) > s.size
+}
+
+// waitRoom waits until the queue fits in its size or s is removed.
+func (s *eventSink[E]) waitRoom() {
+	s.mu.Lock()
+	for len(s.queue) > s.size && !s.removed {
+		s.room.Wait()
+	}
+	s.mu.Unlock()
+}
+
+// run delivers queued events until the queue is empty or s is removed.
+// No lock is held while fn runs or while sending on ch.
+func (s *eventSink[E]) run() {
+	for {
+		s.mu.Lock()
+		if s.removed || len(s.queue) == 0 {
+			s.running = false
+			if s.removed && s.ch != nil {
+				close(s.ch)
+			}
+			s.mu.Unlock()
+			return
+		}
+		e := s.queue[0]
+		s.mu.Unlock()
+
+		if s.fn != nil {
+			s.fn(e)
+		} else {
+			select {
+			case s.ch <- e:
+			case <-s.quit:
+				continue
+			}
+		}
+
+		s.mu.Lock()
+		if !s.removed { // stop has already emptied the queue
+			var zero E
+			s.queue[0] = zero
+			s.queue = s.queue[1:]
+			s.room.Broadcast()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// stop discards the pending events of s, wakes blocked writers and closes ch,
+// either here or, if a dispatcher is running, once it notices.
+func (s *eventSink[E]) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = true
+	s.queue = nil
+	close(s.quit)
+	s.room.Broadcast()
+	if !s.running && s.ch != nil {
+		close(s.ch)
+	}
+}
+
+// eventHub fans events out to subscribers.
+// Publishers hand their events to every subscriber's queue while they still
+// hold the collection's lock, so each subscriber sees the changes in the order
+// they were made. Delivery happens on each subscriber's own dispatcher
+// goroutine, outside every lock, so a slow subscriber neither delays the
+// others nor, unless it uses OverflowBlock, the writers. Subscribers may read
+// the collection and unsubscribe from within a callback.
+type eventHub[E any] struct {
+	mu    sync.Mutex // guards sinks and next
+	sinks []*eventSink[E]
+	next  uint64
+	count int32
+}
+
+// active reports whether the hub has any subscribers.
+func (h *eventHub[E]) active() bool {
+	return atomic.LoadInt32(&h.count) > 0
+}
+
+// subscribers returns the current subscribers. The returned slice is never
+// modified, since add and remove replace h.sinks instead.
+func (h *eventHub[E]) subscribers() []*eventSink[E] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sinks
+}
+
+// add registers s and assigns its identifier.
+func (h *eventHub[E]) add(s *eventSink[E]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.next++
+	s.id = h.next
+	sinks := make([]*eventSink[E], 0, len(h.sinks)+1)
+	h.sinks = append(append(sinks, h.sinks...), s)
+	atomic.AddInt32(&h.count, 1)
+}
+
+// remove unregisters the subscriber with the given identifier, discards its
+// pending events and closes its channel. It may be called from within a
+// subscriber's callback; apart from a callback already running, the
+// subscriber receives no events once remove has returned.
+func (h *eventHub[E]) remove(id uint64) {
+	h.mu.Lock()
+	var removed *eventSink[E]
+	for i, s := range h.sinks {
+		if s.id == id {
+			removed = s
+			sinks := make([]*eventSink[E], 0, len(h.sinks)-1)
+			h.sinks = append(append(sinks, h.sinks[:i]...), h.sinks[i+1:]...)
+			atomic.AddInt32(&h.count, -1)
+			break
+		}
+	}
+	h.mu.Unlock()
+	if removed != nil {
+		removed.stop()
+	}
+}
+
+// unlockAndPublish queues events for every subscriber, runs unlock and then,
+// for OverflowBlock subscribers that are full, waits until they catch up.
+// The caller must hold the collection's lock, which unlock releases.
+func (h *eventHub[E]) unlockAndPublish(unlock func(), events []E) {
+	if len(events) == 0 {
+		unlock()
+		return
+	}
+	var full []*eventSink[E]
+	for _, s := range h.subscribers() {
+		if s.enqueue(events) {
+			full = append(full, s)
+		}
+	}
+	unlock()
+	for _, s := range full {
+		s.waitRoom()
+	}
+}
+
+// subscribeFunc registers fn and returns a function that unregisters it.
+func (h *eventHub[E]) subscribeFunc(filter func(E) bool, fn func(E), opts WatchOptions) func() {
+	s := newEventSink(filter, fn, opts)
+	h.add(s)
+	var once sync.Once
+	return func() {
+		once.Do(func() { h.remove(s.id) })
+	}
+}
+
+// subscribeChan registers a channel subscriber that is removed, and its
+// channel closed, when ctx is done.
+func (h *eventHub[E]) subscribeChan(ctx context.Context, filter func(E) bool, opts WatchOptions) <-chan E {
+	s := newEventSink(filter, nil, opts)
+	h.add(s)
+	go func() {
+		<-ctx.Done()
+		h.remove(s.id)
+	}()
+	return s.ch
+}
@@ -0,0 +1,271 @@
+package threadsafe
+
+// OrderedMap represents a thread-safe map that remembers the order in which
+// keys were inserted. The front of the order holds the oldest key and the
+// back the newest. Setting an existing key updates its value in place.
+type OrderedMap[K comparable, V any] struct {
+	data map[K]*orderedEntry[K, V]
+	root orderedEntry[K, V]
+	mu   rwMutex
+}
+
+// orderedEntry is a key-value pair in the map's doubly linked list.
+// The list is circular through the map's root entry.
+type orderedEntry[K comparable, V any] struct {
+	key   K
+	value V
+	prev  *orderedEntry[K, V]
+	next  *orderedEntry[K, V]
+}
+
+// NewOrderedMap creates a new thread-safe ordered map.
+// Example:
+//
+//	m := threadsafe.NewOrderedMap[string, int]()
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	m := &OrderedMap[K, V]{}
+	m.reset()
+	return m
+}
+
+// reset empties the map. The caller must hold m.mu.
+func (m *OrderedMap[K, V]) reset() {
+	m.data = make(map[K]*orderedEntry[K, V])
+	m.root.next = &m.root
+	m.root.prev = &m.root
+}
+
+// unlink removes e from the list. The caller must hold m.mu.
+func (m *OrderedMap[K, V]) unlink(e *orderedEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+}
+
+// linkAfter inserts e after at. The caller must hold m.mu.
+func (m *OrderedMap[K, V]) linkAfter(e, at *orderedEntry[K, V]) {
+	e.prev = at
+	e.next = at.next
+	at.next.prev = e
+	at.next = e
+}
+
+// set stores value under key, appending new keys at the back. The caller must hold m.mu.
+func (m *OrderedMap[K, V]) set(key K, value V) {
+	if e, ok := m.data[key]; ok {
+		e.value = value
+		return
+	}
+	e := &orderedEntry[K, V]{key: key, value: value}
+	m.linkAfter(e, m.root.prev)
+	m.data[key] = e
+}
+
+// Get retrieves the value associated with the key.
+// It returns the value and a boolean indicating whether the key was found.
+// Example:
+//
+//	value, ok := m.Get("key")
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	m.mu.count("Get")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if e, ok := m.data[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set sets the value for the given key. A new key is added at the back;
+// an existing key keeps its position.
+// Example:
+//
+//	m.Set("key", 100)
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	m.mu.count("Set")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(key, value)
+}
+
+// Delete removes the value associated with the key.
+// Example:
+//
+//	m.Delete("key")
+func (m *OrderedMap[K, V]) Delete(key K) {
+	m.mu.count("Delete")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.data[key]; ok {
+		m.unlink(e)
+		delete(m.data, key)
+	}
+}
+
+// Length returns the number of key-value pairs in the map.
+// Example:
+//
+//	length := m.Length()
+func (m *OrderedMap[K, V]) Length() int {
+	m.mu.count("Length")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// Keys returns the keys from oldest to newest.
+// Example:
+//
+//	keys := m.Keys()
+func (m *OrderedMap[K, V]) Keys() []K {
+	m.mu.count("Keys")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.data))
+	for e := m.root.next; e != &m.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Values returns the values in key order, from oldest to newest.
+// Example:
+//
+//	values := m.Values()
+func (m *OrderedMap[K, V]) Values() []V {
+	m.mu.count("Values")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([]V, 0, len(m.data))
+	for e := m.root.next; e != &m.root; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// Contains checks if the map contains the specified key.
+// Example:
+//
+//	contains := m.Contains("key")
+func (m *OrderedMap[K, V]) Contains(key K) bool {
+	m.mu.count("Contains")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.data[key]
+	return exists
+}
+
+// Clear removes all key-value pairs from the map.
+// Example:
+//
+//	m.Clear()
+func (m *OrderedMap[K, V]) Clear() {
+	m.mu.count("Clear")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reset()
+}
+
+// Copy returns a new thread-safe ordered map with the same entries in the same order.
+// Example:
+//
+//	copyMap := m.Copy()
+func (m *OrderedMap[K, V]) Copy() *OrderedMap[K, V] {
+	m.mu.count("Copy")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := NewOrderedMap[K, V]()
+	for e := m.root.next; e != &m.root; e = e.next {
+		c.set(e.key, e.value)
+	}
+	return c
+}
+
+// MoveToFront makes key the oldest entry.
+// It returns false if the key is not present.
+// Example:
+//
+//	ok := m.MoveToFront("key")
+func (m *OrderedMap[K, V]) MoveToFront(key K) bool {
+	m.mu.count("MoveToFront")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.data[key]
+	if !ok {
+		return false
+	}
+	m.unlink(e)
+	m.linkAfter(e, &m.root)
+	return true
+}
+
+// MoveToBack makes key the newest entry.
+// It returns false if the key is not present.
+// Example:
+//
+//	ok := m.MoveToBack("key")
+func (m *OrderedMap[K, V]) MoveToBack(key K) bool {
+	m.mu.count("MoveToBack")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.data[key]
+	if !ok {
+		return false
+	}
+	m.unlink(e)
+	m.linkAfter(e, m.root.prev)
+	return true
+}
+
+// Oldest returns the entry at the front of the order.
+// The boolean is false if the map is empty.
+// Example:
+//
+//	key, value, ok := m.Oldest()
+func (m *OrderedMap[K, V]) Oldest() (K, V, bool) {
+	m.mu.count("Oldest")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.entry(m.root.next)
+}
+
+// Newest returns the entry at the back of the order.
+// The boolean is false if the map is empty.
+// Example:
+//
+//	key, value, ok := m.Newest()
+func (m *OrderedMap[K, V]) Newest() (K, V, bool) {
+	m.mu.count("Newest")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.entry(m.root.prev)
+}
+
+// entry returns the key and value of e, or false if e is the root.
+func (m *OrderedMap[K, V]) entry(e *orderedEntry[K, V]) (K, V, bool) {
+	if e == &m.root {
+		var key K
+		var value V
+		return key, value, false
+	}
+	return e.key, e.value, true
+}
+
+// Range calls fn for each entry from oldest to newest until fn returns false.
+// The map is read-locked for the whole iteration, so fn must not modify it.
+// Example:
+//
+//	m.Range(func(key string, value int) bool {
+//		fmt.Println(key, value)
+//		return true
+//	})
+func (m *OrderedMap[K, V]) Range(fn func(key K, value V) bool) {
+	m.mu.count("Range")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for e := m.root.next; e != &m.root; e = e.next {
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package threadsafe
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOrderedMap(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	assert.Equal(t, 0, m.Length())
+	assert.Equal(t, []string{}, m.Keys())
+	_, _, ok := m.Oldest()
+	assert.False(t, ok)
+	_, _, ok = m.Newest()
+	assert.False(t, ok)
+}
+
+func TestOrderedMapInsertionOrder(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("c", 3)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 30)
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.Equal(t, []int{30, 1, 2}, m.Values())
+
+	value, ok := m.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 30, value)
+	assert.True(t, m.Contains("a"))
+	assert.Equal(t, 3, m.Length())
+}
+
+func TestOrderedMapDelete(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Delete("b")
+	m.Delete("missing")
+	assert.Equal(t, []string{"a", "c"}, m.Keys())
+	assert.False(t, m.Contains("b"))
+
+	m.Set("b", 4)
+	assert.Equal(t, []string{"a", "c", "b"}, m.Keys())
+}
+
+func TestOrderedMapMove(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+
+	assert.True(t, m.MoveToFront("c"))
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.True(t, m.MoveToBack("c"))
+	assert.Equal(t, []string{"a", "b", "c"}, m.Keys())
+	assert.True(t, m.MoveToBack("c"))
+	assert.Equal(t, []string{"a", "b", "c"}, m.Keys())
+	assert.False(t, m.MoveToFront("missing"))
+	assert.False(t, m.MoveToBack("missing"))
+
+	key, value, ok := m.Oldest()
+	assert.True(t, ok)
+	assert.Equal(t, "a", key)
+	assert.Equal(t, 1, value)
+	key, value, ok = m.Newest()
+	assert.True(t, ok)
+	assert.Equal(t, "c", key)
+	assert.Equal(t, 3, value)
+}
+
+func TestOrderedMapRange(t *testing.T) {
+	m := NewOrderedMap[int, string]()
+	for i := 5; i > 0; i-- {
+		m.Set(i, "v")
+	}
+	var keys []int
+	m.Range(func(key int, value string) bool {
+		keys = append(keys, key)
+		return key != 3
+	})
+	assert.Equal(t, []int{5, 4, 3}, keys)
+}
+
+func TestOrderedMapClearAndCopy(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("b", 2)
+	m.Set("a", 1)
+	c := m.Copy()
+	m.Clear()
+	assert.Equal(t, 0, m.Length())
+	assert.Equal(t, []string{}, m.Keys())
+
+	assert.Equal(t, []string{"b", "a"}, c.Keys())
+	c.Set("c", 3)
+	assert.Equal(t, 0, m.Length())
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(b))
+
+	m.Set("zeta", 1)
+	m.Set("alpha", 2)
+	m.Set("<mid>", 3)
+	b, err = json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"zeta":1,"alpha":2,"\u003cmid\u003e":3}`, string(b))
+
+	decoded := NewOrderedMap[string, int]()
+	decoded.Set("stale", 0)
+	assert.NoError(t, json.Unmarshal(b, decoded))
+	assert.Equal(t, []string{"zeta", "alpha", "<mid>"}, decoded.Keys())
+	assert.Equal(t, []int{1, 2, 3}, decoded.Values())
+
+	assert.NoError(t, json.Unmarshal([]byte(`null`), decoded))
+	assert.Equal(t, 0, decoded.Length())
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), decoded))
+}
+
+func TestOrderedMapJSONIntKeys(t *testing.T) {
+	m := NewOrderedMap[int, []string]()
+	m.Set(10, []string{"x"})
+	m.Set(-2, nil)
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"10":["x"],"-2":null}`, string(b))
+
+	decoded := NewOrderedMap[int, []string]()
+	assert.NoError(t, json.Unmarshal(b, decoded))
+	assert.Equal(t, []int{10, -2}, decoded.Keys())
+	assert.Error(t, json.Unmarshal([]byte(`{"x":[]}`), decoded))
+}
+
+func TestOrderedMapConcurrent(t *testing.T) {
+	m := NewOrderedMap[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := g*100 + i
+				m.Set(key, i)
+				m.MoveToFront(key)
+				m.Get(key)
+				if i%2 == 0 {
+					m.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 400, m.Length())
+	assert.Len(t, m.Keys(), 400)
+}
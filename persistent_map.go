@@ -0,0 +1,310 @@
+package threadsafe
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Names of the files a PersistentMap keeps in its directory.
+const (
+	persistentMapSnapshot = "snapshot"
+	persistentMapLog      = "wal"
+)
+
+// defaultCompactThreshold is the log size that triggers compaction when none is configured.
+const defaultCompactThreshold = 4 << 20
+
+// Operations recorded in the write-ahead log.
+const (
+	walSet byte = iota + 1
+	walDelete
+	walClear
+)
+
+// ErrClosed is returned when using a persistent collection after Close.
+var ErrClosed = errors.New("threadsafe: collection is closed")
+
+// PersistentMapOptions configures a PersistentMap.
+// The zero value uses GobCodec, a 4 MiB compaction threshold and syncs every write.
+type PersistentMapOptions struct {
+	// Codec encodes log records and snapshots. Defaults to GobCodec.
+	Codec Codec
+	// CompactThreshold is the log size in bytes above which the log is
+	// folded into a new snapshot. Defaults to 4 MiB.
+	CompactThreshold int64
+	// NoSync skips fsync after each log append, trading durability for speed.
+	NoSync bool
+	// OnCompactError, if set, is called when a compaction triggered by a
+	// write fails. The write itself has been committed and reports success;
+	// compaction is retried on the next write.
+	OnCompactError func(error)
+}
+
+// walRecord is a single logged mutation.
+type walRecord[K comparable, V any] struct {
+	Op    byte
+	Key   K
+	Value V
+}
+
+// PersistentMap is a thread-safe map whose mutations are written to an
+// append-only log before they are applied, so its contents survive restarts.
+// The log is replayed on open and compacted into a snapshot once it grows
+// beyond the configured threshold.
+type PersistentMap[K comparable, V any] struct {
+	m       *Map[K, V]
+	mu      mutex
+	dir     string
+	opts    PersistentMapOptions
+	log     *os.File
+	logSize int64
+}
+
+// OpenPersistentMap opens or creates a persistent map stored in dir.
+// Example:
+//
+//	m, err := threadsafe.OpenPersistentMap[string, int]("data/cache", threadsafe.PersistentMapOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	defer m.Close()
+func OpenPersistentMap[K comparable, V any](dir string, opts PersistentMapOptions) (*PersistentMap[K, V], error) {
+	if opts.Codec == nil {
+		opts.Codec = GobCodec
+	}
+	if opts.CompactThreshold <= 0 {
+		opts.CompactThreshold = defaultCompactThreshold
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	m, err := LoadMap[K, V](filepath.Join(dir, persistentMapSnapshot), opts.Codec)
+	if os.IsNotExist(err) {
+		m, err = NewMap[K, V](), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(filepath.Join(dir, persistentMapLog), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	pm := &PersistentMap[K, V]{m: m, dir: dir, opts: opts, log: log}
+	if err := pm.replay(); err != nil {
+		log.Close()
+		return nil, err
+	}
+	return pm, nil
+}
+
+// replay applies every intact log record to the in-memory map and truncates
+// a torn or corrupt tail left behind by a crash. A record that passes its
+// checksum but cannot be decoded, for example because the map was opened
+// with different types or codec, is returned as an error and the log is left
+// untouched.
+func (pm *PersistentMap[K, V]) replay() error {
+	info, err := pm.log.Stat()
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(pm.log)
+	var offset int64
+	for {
+		payload, err := readRecord(r, info.Size()-offset)
+		if err == io.EOF {
+			break
+		}
+		if err == errBadRecord {
+			return pm.truncateLog(offset)
+		}
+		if err != nil {
+			return err
+		}
+		var rec walRecord[K, V]
+		if err := pm.opts.Codec.Unmarshal(payload, &rec); err != nil {
+			return fmt.Errorf("threadsafe: decoding log record at offset %d: %w", offset, err)
+		}
+		pm.apply(rec)
+		offset += int64(recordHeaderSize + len(payload))
+	}
+	return pm.truncateLog(offset)
+}
+
+// truncateLog cuts the log to size bytes and positions the next append there.
+func (pm *PersistentMap[K, V]) truncateLog(size int64) error {
+	if err := pm.log.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := pm.log.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	pm.logSize = size
+	return nil
+}
+
+// rollback discards whatever part of a failed append reached the log, so
+// that later records are not written after torn bytes that replay would
+// stop at. It returns err, joined with any error from the rollback itself.
+func (pm *PersistentMap[K, V]) rollback(err error) error {
+	if terr := pm.truncateLog(pm.logSize); terr != nil {
+		return errors.Join(err, terr)
+	}
+	return err
+}
+
+// apply performs a logged mutation on the in-memory map.
+func (pm *PersistentMap[K, V]) apply(rec walRecord[K, V]) {
+	switch rec.Op {
+	case walSet:
+		pm.m.Set(rec.Key, rec.Value)
+	case walDelete:
+		pm.m.Delete(rec.Key)
+	case walClear:
+		pm.m.Clear()
+	}
+}
+
+// commit appends rec to the log, applies it and compacts the log if needed.
+// Once rec is in the log the write has succeeded, so a failed compaction is
+// reported through OnCompactError rather than returned.
+func (pm *PersistentMap[K, V]) commit(rec walRecord[K, V]) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.log == nil {
+		return ErrClosed
+	}
+	payload, err := pm.opts.Codec.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	buf := frameRecord(payload)
+	if _, err := pm.log.Write(buf); err != nil {
+		return pm.rollback(err)
+	}
+	if !pm.opts.NoSync {
+		if err := pm.log.Sync(); err != nil {
+			return pm.rollback(err)
+		}
+	}
+	pm.logSize += int64(len(buf))
+	pm.apply(rec)
+	if pm.logSize > pm.opts.CompactThreshold {
+		if err := pm.compact(); err != nil && pm.opts.OnCompactError != nil {
+			pm.opts.OnCompactError(err)
+		}
+	}
+	return nil
+}
+
+// compact writes a snapshot of the map and empties the log. The caller must hold pm.mu.
+// If the process crashes after the snapshot is written but before the log is
+// emptied, replaying the old log over the new snapshot yields the same contents.
+func (pm *PersistentMap[K, V]) compact() error {
+	if err := pm.m.SaveTo(filepath.Join(pm.dir, persistentMapSnapshot), pm.opts.Codec); err != nil {
+		return err
+	}
+	if err := pm.truncateLog(0); err != nil {
+		return err
+	}
+	return pm.log.Sync()
+}
+
+// Get retrieves the value associated with the key.
+// It returns the value and a boolean indicating whether the key was found.
+// Example:
+//
+//	value, ok := m.Get("key")
+func (pm *PersistentMap[K, V]) Get(key K) (V, bool) {
+	return pm.m.Get(key)
+}
+
+// Set durably sets the value for the given key.
+// Example:
+//
+//	err := m.Set("key", 100)
+func (pm *PersistentMap[K, V]) Set(key K, value V) error {
+	return pm.commit(walRecord[K, V]{Op: walSet, Key: key, Value: value})
+}
+
+// Delete durably removes the value associated with the key.
+// Example:
+//
+//	err := m.Delete("key")
+func (pm *PersistentMap[K, V]) Delete(key K) error {
+	return pm.commit(walRecord[K, V]{Op: walDelete, Key: key})
+}
+
+// Clear durably removes all key-value pairs from the map.
+// Example:
+//
+//	err := m.Clear()
+func (pm *PersistentMap[K, V]) Clear() error {
+	return pm.commit(walRecord[K, V]{Op: walClear})
+}
+
+// Length returns the number of key-value pairs in the map.
+// Example:
+//
+//	length := m.Length()
+func (pm *PersistentMap[K, V]) Length() int {
+	return pm.m.Length()
+}
+
+// Keys returns a slice of all keys present in the map.
+// Example:
+//
+//	keys := m.Keys()
+func (pm *PersistentMap[K, V]) Keys() []K {
+	return pm.m.Keys()
+}
+
+// Values returns a slice of all values present in the map.
+// Example:
+//
+//	values := m.Values()
+func (pm *PersistentMap[K, V]) Values() []V {
+	return pm.m.Values()
+}
+
+// Contains checks if the map contains the specified key.
+// Example:
+//
+//	contains := m.Contains("key")
+func (pm *PersistentMap[K, V]) Contains(key K) bool {
+	return pm.m.Contains(key)
+}
+
+// Compact folds the log into a new snapshot immediately.
+// Example:
+//
+//	err := m.Compact()
+func (pm *PersistentMap[K, V]) Compact() error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.log == nil {
+		return ErrClosed
+	}
+	return pm.compact()
+}
+
+// Close flushes and closes the log. Reads keep working after Close; writes return ErrClosed.
+// Example:
+//
+//	err := m.Close()
+func (pm *PersistentMap[K, V]) Close() error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.log == nil {
+		return ErrClosed
+	}
+	err := pm.log.Sync()
+	if cerr := pm.log.Close(); err == nil {
+		err = cerr
+	}
+	pm.log = nil
+	return err
+}
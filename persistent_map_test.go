@@ -0,0 +1,198 @@
+package threadsafe
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentMapReplay(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Set("a", 1))
+	assert.NoError(t, m.Set("b", 2))
+	assert.NoError(t, m.Delete("a"))
+	assert.NoError(t, m.Close())
+
+	m, err = OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.False(t, m.Contains("a"))
+	value, ok := m.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+}
+
+func TestPersistentMapCompaction(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentMapOptions{Codec: JSONCodec, CompactThreshold: 256, NoSync: true}
+	m, err := OpenPersistentMap[int, int](dir, opts)
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, m.Set(i%10, i))
+	}
+	assert.NoError(t, m.Close())
+
+	info, err := os.Stat(filepath.Join(dir, persistentMapLog))
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(256))
+	_, err = os.Stat(filepath.Join(dir, persistentMapSnapshot))
+	assert.NoError(t, err)
+
+	m, err = OpenPersistentMap[int, int](dir, opts)
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, 10, m.Length())
+	value, _ := m.Get(9)
+	assert.Equal(t, 99, value)
+}
+
+func TestPersistentMapTornTail(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Set("a", 1))
+	assert.NoError(t, m.Set("b", 2))
+	assert.NoError(t, m.Close())
+
+	path := filepath.Join(dir, persistentMapLog)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(path, info.Size()-3))
+
+	m, err = OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.True(t, m.Contains("a"))
+	assert.False(t, m.Contains("b"))
+	assert.NoError(t, m.Set("c", 3))
+	assert.NoError(t, m.Close())
+
+	m, err = OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.ElementsMatch(t, []string{"a", "c"}, m.Keys())
+}
+
+func TestPersistentMapRollbackFailedAppend(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Set("a", 1))
+
+	// Simulate an append that failed after writing part of a record.
+	_, err = m.log.Write([]byte{0xff, 0xff, 0xff})
+	assert.NoError(t, err)
+	assert.EqualError(t, m.rollback(errors.New("disk full")), "disk full")
+
+	assert.NoError(t, m.Set("b", 2))
+	assert.NoError(t, m.Close())
+
+	m, err = OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.ElementsMatch(t, []string{"a", "b"}, m.Keys())
+}
+
+func TestPersistentMapCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Set("a", 1))
+	assert.NoError(t, m.Close())
+
+	path := filepath.Join(dir, persistentMapLog)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+
+	m, err = OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, 0, m.Length())
+}
+
+func TestPersistentMapDecodeError(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Set("a", 1))
+	assert.NoError(t, m.Close())
+	path := filepath.Join(dir, persistentMapLog)
+	before, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	_, err = OpenPersistentMap[string, string](dir, PersistentMapOptions{})
+	assert.Error(t, err)
+	after, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	m, err = OpenPersistentMap[string, int](dir, PersistentMapOptions{})
+	assert.NoError(t, err)
+	defer m.Close()
+	value, _ := m.Get("a")
+	assert.Equal(t, 1, value)
+}
+
+func TestPersistentMapCompactErrorKeepsWrite(t *testing.T) {
+	dir := t.TempDir()
+	var compactErr error
+	opts := PersistentMapOptions{CompactThreshold: 1, NoSync: true, OnCompactError: func(err error) { compactErr = err }}
+	m, err := OpenPersistentMap[string, int](dir, opts)
+	assert.NoError(t, err)
+	// A non-empty directory in place of the snapshot makes compaction fail.
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, persistentMapSnapshot, "x"), 0o755))
+
+	assert.NoError(t, m.Set("a", 1))
+	assert.Error(t, compactErr)
+	assert.NoError(t, m.Close())
+
+	assert.NoError(t, os.RemoveAll(filepath.Join(dir, persistentMapSnapshot)))
+	m, err = OpenPersistentMap[string, int](dir, opts)
+	assert.NoError(t, err)
+	defer m.Close()
+	value, _ := m.Get("a")
+	assert.Equal(t, 1, value)
+}
+
+func TestPersistentMapClosed(t *testing.T) {
+	m, err := OpenPersistentMap[string, int](t.TempDir(), PersistentMapOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Set("a", 1))
+	assert.NoError(t, m.Close())
+	assert.ErrorIs(t, m.Set("b", 2), ErrClosed)
+	assert.ErrorIs(t, m.Close(), ErrClosed)
+	assert.True(t, m.Contains("a"))
+}
+
+func TestPersistentMapConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentMapOptions{CompactThreshold: 1024, NoSync: true}
+	m, err := OpenPersistentMap[int, int](dir, opts)
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, m.Set(i*50+j, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.NoError(t, m.Clear())
+	assert.NoError(t, m.Set(1, 1))
+	assert.NoError(t, m.Close())
+
+	m, err = OpenPersistentMap[int, int](dir, opts)
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, []int{1}, m.Keys())
+}
@@ -1,15 +1,18 @@
 package threadsafe
 
 import (
-	"sync"
-
 	"github.com/golang-collections/collections/queue"
 )
 
 // Queue is a thread-safe queue.
 type Queue struct {
-	q  *queue.Queue
-	mu sync.Mutex
+	q *queue.Queue
+	// front holds elements put back at the front of the queue, the first last.
+	front []interface{}
+	mu    mutex
+	id    lockID
+	hooks eventHub[queueEvent]
+	added waiters
 }
 
 // NewQueue creates a new thread-safe queue.
@@ -21,26 +24,39 @@ func NewQueue() *Queue {
 
 // Enqueue adds an element to the queue.
 func (q *Queue) Enqueue(value interface{}) {
+	q.mu.count("Enqueue")
+	var events []queueEvent
 	q.mu.Lock()
-	defer q.mu.Unlock()
+	defer func() { q.unlockAndNotify(events) }()
 	q.q.Enqueue(value)
+	q.added.broadcast()
+	if q.hooks.active() {
+		events = []queueEvent{{enqueued: true, value: value}}
+	}
 }
 
 // Dequeue removes and returns an element from the queue.
 func (q *Queue) Dequeue() (interface{}, bool) {
+	q.mu.count("Dequeue")
+	var events []queueEvent
 	q.mu.Lock()
-	defer q.mu.Unlock()
-	if q.q.Len() == 0 {
+	defer func() { q.unlockAndNotify(events) }()
+	if q.len() == 0 {
 		return nil, false
 	}
-	return q.q.Dequeue(), true
+	value := q.dequeue()
+	if q.hooks.active() {
+		events = []queueEvent{{value: value}}
+	}
+	return value, true
 }
 
 // Len returns the number of elements in the queue.
 func (q *Queue) Len() int {
+	q.mu.count("Len")
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	return q.q.Len()
+	return q.len()
 }
 
 // Peek returns the element at the front of the queue without removing it.
@@ -48,11 +64,15 @@ func (q *Queue) Len() int {
 //
 //	value, ok := q.Peek()
 func (q *Queue) Peek() (interface{}, bool) {
+	q.mu.count("Peek")
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	if q.q.Len() == 0 {
+	if q.len() == 0 {
 		return nil, false
 	}
+	if n := len(q.front); n > 0 {
+		return q.front[n-1], true
+	}
 	return q.q.Peek(), true
 }
 
@@ -61,9 +81,10 @@ func (q *Queue) Peek() (interface{}, bool) {
 //
 //	isEmpty := q.IsEmpty()
 func (q *Queue) IsEmpty() bool {
+	q.mu.count("IsEmpty")
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	return q.q.Len() == 0
+	return q.len() == 0
 }
 
 // Clear removes all elements from the queue.
@@ -71,9 +92,10 @@ func (q *Queue) IsEmpty() bool {
 //
 //	q.Clear()
 func (q *Queue) Clear() {
+	q.mu.count("Clear")
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.q = queue.New()
+	q.reset(nil)
 }
 
 // Values returns a slice of all elements in the queue.
@@ -81,11 +103,49 @@ func (q *Queue) Clear() {
 //
 //	values := q.Values()
 func (q *Queue) Values() []interface{} {
+	q.mu.count("Values")
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	return q.values()
+}
+
+// len returns the number of elements in the queue. The caller must hold q.mu.
+func (q *Queue) len() int {
+	return len(q.front) + q.q.Len()
+}
 
+// dequeue removes and returns the front element of a non-empty queue.
+// The caller must hold q.mu.
+func (q *Queue) dequeue() interface{} {
+	if n := len(q.front); n > 0 {
+		value := q.front[n-1]
+		q.front[n-1] = nil
+		q.front = q.front[:n-1]
+		return value
+	}
+	return q.q.Dequeue()
+}
+
+// reset replaces the queue's contents with values, the first at the front.
+// The caller must hold q.mu.
+func (q *Queue) reset(values []interface{}) {
+	q.front = nil
+	q.q = queue.New()
+	for _, value := range values {
+		q.q.Enqueue(value)
+	}
+	if len(values) > 0 {
+		q.added.broadcast()
+	}
+}
+
+// values returns all elements in the queue. The caller must hold q.mu.
+func (q *Queue) values() []interface{} {
 	// Create a temporary slice to hold the values
-	values := make([]interface{}, 0, q.q.Len())
+	values := make([]interface{}, 0, q.len())
+	for i := len(q.front) - 1; i >= 0; i-- {
+		values = append(values, q.front[i])
+	}
 
 	// Temporarily dequeue all elements to capture them
 	length := q.q.Len()
@@ -0,0 +1,45 @@
+package threadsafe
+
+// queueEvent describes an element entering or leaving a Queue.
+type queueEvent struct {
+	enqueued bool
+	value    interface{}
+}
+
+// OnEnqueue registers fn to be called with every element added by Enqueue
+// or put back by a cancelled Chan, and returns a function that unregisters it.
+// Each hook runs on a goroutine of its own, outside the queue's lock, after
+// the change is made; calls happen one at a time in the order of the changes.
+// opts controls buffering as for Map.Watch: by default, changes made while 16
+// are already waiting for the hook are dropped. Hooks may read the queue and
+// unregister themselves, but must not modify the queue.
+// Example:
+//
+//	stop := q.OnEnqueue(func(value interface{}) {
+//		fmt.Println("enqueued", value)
+//	})
+//	defer stop()
+func (q *Queue) OnEnqueue(fn func(value interface{}), opts ...WatchOptions) func() {
+	return q.hooks.subscribeFunc(func(e queueEvent) bool { return e.enqueued }, func(e queueEvent) {
+		fn(e.value)
+	}, watchOptions(opts))
+}
+
+// OnDequeue registers fn to be called with every element removed by Dequeue,
+// and returns a function that unregisters it. See OnEnqueue for when hooks run.
+// Example:
+//
+//	stop := q.OnDequeue(func(value interface{}) {
+//		fmt.Println("dequeued", value)
+//	})
+//	defer stop()
+func (q *Queue) OnDequeue(fn func(value interface{}), opts ...WatchOptions) func() {
+	return q.hooks.subscribeFunc(func(e queueEvent) bool { return !e.enqueued }, func(e queueEvent) {
+		fn(e.value)
+	}, watchOptions(opts))
+}
+
+// unlockAndNotify releases q.mu and runs hooks for events.
+func (q *Queue) unlockAndNotify(events []queueEvent) {
+	q.hooks.unlockAndPublish(q.mu.Unlock, events)
+}
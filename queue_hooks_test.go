@@ -0,0 +1,103 @@
+package threadsafe
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueHooks(t *testing.T) {
+	queue := NewQueue()
+	var mu sync.Mutex
+	var enqueued, dequeued []interface{}
+	queue.OnEnqueue(func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		enqueued = append(enqueued, value)
+	})
+	stop := queue.OnDequeue(func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		dequeued = append(dequeued, value)
+	})
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	queue.Dequeue()
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(enqueued) == 2 && len(dequeued) == 1
+	}, time.Second, time.Millisecond)
+	stop()
+	queue.Dequeue()
+	queue.Dequeue()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []interface{}{1, 2}, enqueued)
+	assert.Equal(t, []interface{}{1}, dequeued)
+}
+
+func TestQueueHooksOrderedUnderConcurrency(t *testing.T) {
+	queue := NewQueue()
+	var mu sync.Mutex
+	var enqueued, dequeued []interface{}
+	queue.OnEnqueue(func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		enqueued = append(enqueued, value)
+	}, WatchOptions{Overflow: OverflowBlock})
+	queue.OnDequeue(func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		dequeued = append(dequeued, value)
+	}, WatchOptions{Overflow: OverflowBlock})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			queue.Enqueue(i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			queue.Dequeue()
+		}()
+	}
+	wg.Wait()
+	for queue.Len() > 0 {
+		queue.Dequeue()
+	}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(enqueued) == 100 && len(dequeued) == 100
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	// Dequeues are reported in the same order the elements were enqueued.
+	assert.Equal(t, enqueued, dequeued)
+}
+
+func TestQueueHookReadsUnderConcurrentWrites(t *testing.T) {
+	queue := NewQueue()
+	queue.OnEnqueue(func(value interface{}) {
+		queue.Len()
+		queue.Peek()
+	})
+	waitOrFail(t, func() {
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					queue.Enqueue(i)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+	assert.Equal(t, 2000, queue.Len())
+}
@@ -0,0 +1,58 @@
+package threadsafe
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// recordHeaderSize is the size of a record header: payload length and CRC-32.
+const recordHeaderSize = 8
+
+// errBadRecord reports a torn or corrupt record.
+var errBadRecord = errors.New("threadsafe: bad record")
+
+// frameRecord prefixes payload with its length and checksum.
+func frameRecord(payload []byte) []byte {
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[recordHeaderSize:], payload)
+	return buf
+}
+
+// readRecord reads one framed record from r. remaining is the number of bytes
+// left in the underlying file and bounds the payload size. It returns io.EOF at
+// a clean end, errBadRecord for a torn or corrupt record and any other read
+// error as is.
+func readRecord(r *bufio.Reader, remaining int64) ([]byte, error) {
+	header := make([]byte, recordHeaderSize)
+	if n, err := io.ReadFull(r, header); err != nil {
+		if n == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, shortRead(err)
+	}
+	size := int64(binary.LittleEndian.Uint32(header[0:4]))
+	if recordHeaderSize+size > remaining {
+		return nil, errBadRecord
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, shortRead(err)
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, errBadRecord
+	}
+	return payload, nil
+}
+
+// shortRead maps a record cut short by the end of the file to errBadRecord.
+func shortRead(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errBadRecord
+	}
+	return err
+}
@@ -0,0 +1,234 @@
+package threadsafe
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultVisibilityTimeout is the visibility timeout used when none is configured.
+const defaultVisibilityTimeout = 30 * time.Second
+
+// Receipt identifies a single delivery of an item from a ReliableQueue.
+type Receipt uint64
+
+// ReliableQueueOptions configures a ReliableQueue.
+type ReliableQueueOptions[T any] struct {
+	// VisibilityTimeout is how long a received item stays hidden before it is
+	// delivered again unless acknowledged. Defaults to 30 seconds.
+	VisibilityTimeout time.Duration
+	// MaxDeliveries is the number of deliveries after which an unacknowledged
+	// item is moved to the dead-letter queue. Zero means unlimited.
+	MaxDeliveries int
+	// DeadLetter receives items that exceeded MaxDeliveries. If nil, the queue
+	// creates its own, available through DeadLetters.
+	DeadLetter *ReliableQueue[T]
+}
+
+// reliableItem is a queued value and the number of times it was delivered.
+type reliableItem[T any] struct {
+	value      T
+	deliveries int
+}
+
+// inflightItem is a delivered item awaiting acknowledgement.
+type inflightItem[T any] struct {
+	item     *reliableItem[T]
+	receipt  Receipt
+	deadline time.Time
+}
+
+// ReliableQueue is a thread-safe FIFO queue with acknowledgements.
+// Receive hides an item for the visibility timeout instead of removing it;
+// Ack removes it for good, while Nack or an expired timeout makes it
+// available again. Items delivered MaxDeliveries times without an Ack
+// are moved to a dead-letter queue.
+type ReliableQueue[T any] struct {
+	mu          mutex
+	opts        ReliableQueueOptions[T]
+	ready       []*reliableItem[T]
+	inflight    map[Receipt]*inflightItem[T]
+	nextReceipt Receipt
+	now         func() time.Time
+}
+
+// NewReliableQueue creates a new reliable queue.
+// Example:
+//
+//	q := threadsafe.NewReliableQueue(threadsafe.ReliableQueueOptions[Job]{
+//		VisibilityTimeout: time.Minute,
+//		MaxDeliveries:     5,
+//	})
+func NewReliableQueue[T any](opts ReliableQueueOptions[T]) *ReliableQueue[T] {
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = defaultVisibilityTimeout
+	}
+	if opts.MaxDeliveries > 0 && opts.DeadLetter == nil {
+		opts.DeadLetter = NewReliableQueue(ReliableQueueOptions[T]{VisibilityTimeout: opts.VisibilityTimeout})
+	}
+	return &ReliableQueue[T]{
+		opts:     opts,
+		inflight: make(map[Receipt]*inflightItem[T]),
+		now:      time.Now,
+	}
+}
+
+// Enqueue adds an element to the queue.
+// Example:
+//
+//	q.Enqueue(job)
+func (q *ReliableQueue[T]) Enqueue(value T) {
+	q.mu.count("Enqueue")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.ready = append(q.ready, &reliableItem[T]{value: value})
+}
+
+// Receive returns the next visible element and a receipt for acknowledging it.
+// The element stays hidden for the visibility timeout. The boolean is false if
+// no element is visible.
+// Example:
+//
+//	job, receipt, ok := q.Receive()
+func (q *ReliableQueue[T]) Receive() (T, Receipt, bool) {
+	q.mu.count("Receive")
+	var dead []T
+	defer func() { q.deadLetter(dead) }()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dead = q.reclaim()
+	if len(q.ready) == 0 {
+		var zero T
+		return zero, 0, false
+	}
+	item := q.ready[0]
+	q.ready[0] = nil
+	q.ready = q.ready[1:]
+	item.deliveries++
+	q.nextReceipt++
+	q.inflight[q.nextReceipt] = &inflightItem[T]{
+		item:     item,
+		receipt:  q.nextReceipt,
+		deadline: q.now().Add(q.opts.VisibilityTimeout),
+	}
+	return item.value, q.nextReceipt, true
+}
+
+// Ack removes a received element for good.
+// It returns false if the receipt is unknown or its visibility timeout has expired.
+// Example:
+//
+//	ok := q.Ack(receipt)
+func (q *ReliableQueue[T]) Ack(receipt Receipt) bool {
+	q.mu.count("Ack")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	f, ok := q.inflight[receipt]
+	if !ok || !q.now().Before(f.deadline) {
+		return false
+	}
+	delete(q.inflight, receipt)
+	return true
+}
+
+// Nack makes a received element visible again immediately, at the front of the queue.
+// It returns false if the receipt is unknown or its visibility timeout has expired.
+// Example:
+//
+//	ok := q.Nack(receipt)
+func (q *ReliableQueue[T]) Nack(receipt Receipt) bool {
+	q.mu.count("Nack")
+	var dead []T
+	defer func() { q.deadLetter(dead) }()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	f, ok := q.inflight[receipt]
+	if !ok || !q.now().Before(f.deadline) {
+		return false
+	}
+	delete(q.inflight, receipt)
+	dead = q.requeue([]*reliableItem[T]{f.item})
+	return true
+}
+
+// reclaim makes elements whose visibility timeout expired visible again.
+// It returns the values that must be moved to the dead-letter queue.
+// The caller must hold q.mu.
+func (q *ReliableQueue[T]) reclaim() []T {
+	now := q.now()
+	var expired []*inflightItem[T]
+	for receipt, f := range q.inflight {
+		if !now.Before(f.deadline) {
+			expired = append(expired, f)
+			delete(q.inflight, receipt)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].receipt < expired[j].receipt })
+	items := make([]*reliableItem[T], len(expired))
+	for i, f := range expired {
+		items[i] = f.item
+	}
+	return q.requeue(items)
+}
+
+// requeue puts items back at the front of the queue in order, except for those
+// that reached MaxDeliveries, whose values are returned. The caller must hold q.mu.
+func (q *ReliableQueue[T]) requeue(items []*reliableItem[T]) []T {
+	var dead []T
+	keep := make([]*reliableItem[T], 0, len(items)+len(q.ready))
+	for _, item := range items {
+		if q.opts.MaxDeliveries > 0 && item.deliveries >= q.opts.MaxDeliveries {
+			dead = append(dead, item.value)
+			continue
+		}
+		keep = append(keep, item)
+	}
+	q.ready = append(keep, q.ready...)
+	return dead
+}
+
+// deadLetter enqueues values on the dead-letter queue. It must be called without q.mu held.
+func (q *ReliableQueue[T]) deadLetter(values []T) {
+	for _, value := range values {
+		q.opts.DeadLetter.Enqueue(value)
+	}
+}
+
+// DeadLetters returns the queue that receives elements exceeding MaxDeliveries,
+// or nil if deliveries are unlimited.
+// Example:
+//
+//	poison, receipt, ok := q.DeadLetters().Receive()
+func (q *ReliableQueue[T]) DeadLetters() *ReliableQueue[T] {
+	return q.opts.DeadLetter
+}
+
+// Len returns the number of visible elements in the queue.
+// Example:
+//
+//	length := q.Len()
+func (q *ReliableQueue[T]) Len() int {
+	q.mu.count("Len")
+	var dead []T
+	defer func() { q.deadLetter(dead) }()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dead = q.reclaim()
+	return len(q.ready)
+}
+
+// InFlight returns the number of received elements awaiting acknowledgement.
+// Example:
+//
+//	pending := q.InFlight()
+func (q *ReliableQueue[T]) InFlight() int {
+	q.mu.count("InFlight")
+	var dead []T
+	defer func() { q.deadLetter(dead) }()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dead = q.reclaim()
+	return len(q.inflight)
+}
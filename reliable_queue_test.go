@@ -0,0 +1,140 @@
+package threadsafe
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock for visibility timeouts.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestReliableQueue(opts ReliableQueueOptions[string]) (*ReliableQueue[string], *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	q := NewReliableQueue(opts)
+	q.now = clock.Now
+	if q.opts.DeadLetter != nil {
+		q.opts.DeadLetter.now = clock.Now
+	}
+	return q, clock
+}
+
+func TestReliableQueueAck(t *testing.T) {
+	q, clock := newTestReliableQueue(ReliableQueueOptions[string]{VisibilityTimeout: time.Second})
+	q.Enqueue("a")
+	value, receipt, ok := q.Receive()
+	assert.True(t, ok)
+	assert.Equal(t, "a", value)
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, 1, q.InFlight())
+	assert.True(t, q.Ack(receipt))
+	assert.False(t, q.Ack(receipt))
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, 0, q.Len())
+	_, _, ok = q.Receive()
+	assert.False(t, ok)
+}
+
+func TestReliableQueueVisibilityTimeout(t *testing.T) {
+	q, clock := newTestReliableQueue(ReliableQueueOptions[string]{VisibilityTimeout: time.Second})
+	q.Enqueue("a")
+	q.Enqueue("b")
+	_, receipt, _ := q.Receive()
+
+	clock.Advance(time.Second)
+	assert.False(t, q.Ack(receipt))
+	assert.Equal(t, 2, q.Len())
+	value, _, ok := q.Receive()
+	assert.True(t, ok)
+	assert.Equal(t, "a", value)
+}
+
+func TestReliableQueueNack(t *testing.T) {
+	q, _ := newTestReliableQueue(ReliableQueueOptions[string]{})
+	q.Enqueue("a")
+	q.Enqueue("b")
+	_, receipt, _ := q.Receive()
+	assert.True(t, q.Nack(receipt))
+	assert.False(t, q.Nack(receipt))
+	value, _, _ := q.Receive()
+	assert.Equal(t, "a", value)
+}
+
+func TestReliableQueueDeadLetter(t *testing.T) {
+	q, clock := newTestReliableQueue(ReliableQueueOptions[string]{
+		VisibilityTimeout: time.Second,
+		MaxDeliveries:     2,
+	})
+	q.Enqueue("poison")
+	_, receipt, _ := q.Receive()
+	q.Nack(receipt)
+	q.Receive()
+	clock.Advance(time.Second)
+
+	assert.Equal(t, 0, q.Len())
+	_, _, ok := q.Receive()
+	assert.False(t, ok)
+	value, _, ok := q.DeadLetters().Receive()
+	assert.True(t, ok)
+	assert.Equal(t, "poison", value)
+}
+
+func TestReliableQueueCustomDeadLetter(t *testing.T) {
+	dlq := NewReliableQueue(ReliableQueueOptions[string]{})
+	q := NewReliableQueue(ReliableQueueOptions[string]{MaxDeliveries: 1, DeadLetter: dlq})
+	q.Enqueue("a")
+	_, receipt, _ := q.Receive()
+	q.Nack(receipt)
+	assert.Same(t, dlq, q.DeadLetters())
+	assert.Equal(t, 1, dlq.Len())
+}
+
+func TestReliableQueueConcurrentConsumers(t *testing.T) {
+	q := NewReliableQueue(ReliableQueueOptions[int]{})
+	for i := 0; i < 200; i++ {
+		q.Enqueue(i)
+	}
+	var mu sync.Mutex
+	seen := make(map[int]int)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value, receipt, ok := q.Receive()
+				if !ok {
+					return
+				}
+				mu.Lock()
+				seen[value]++
+				mu.Unlock()
+				q.Ack(receipt)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 200)
+	for _, n := range seen {
+		assert.Equal(t, 1, n)
+	}
+	assert.Equal(t, 0, q.InFlight())
+}
@@ -0,0 +1,458 @@
+package threadsafe
+
+import (
+	"cmp"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// skipMaxLevel is the maximum height of a skip list, enough for 4^32 entries
+// at the list's branching factor.
+const skipMaxLevel = 32
+
+// SkipList represents a concurrent sorted map.
+// Readers never block: lookups and iteration follow atomically published
+// links without taking any lock. Writers lock only the nodes whose links
+// they change, so updates to different parts of the list proceed in parallel.
+// Iteration is weakly consistent: it reflects some of the updates made while
+// it runs, never returns a key twice and always returns keys in order.
+type SkipList[K, V any] struct {
+	compare func(a, b K) int
+	head    *skipNode[K, V]
+	length  atomic.Int64
+	stats   statsHandle
+}
+
+// skipNode is an entry of a SkipList. A node is part of the list once
+// fullyLinked is set and until marked is set.
+type skipNode[K, V any] struct {
+	key         K
+	value       atomic.Pointer[V]
+	next        []atomic.Pointer[skipNode[K, V]]
+	mu          sync.Mutex
+	marked      atomic.Bool
+	fullyLinked atomic.Bool
+}
+
+// NewSkipList creates a new concurrent skip list sorted by the natural order of its keys.
+// Example:
+//
+//	l := threadsafe.NewSkipList[int, string]()
+func NewSkipList[K cmp.Ordered, V any]() *SkipList[K, V] {
+	return NewSkipListFunc[K, V](cmp.Compare[K])
+}
+
+// NewSkipListFunc creates a new concurrent skip list sorted by compare, which
+// returns a negative number, zero or a positive number when a is less than,
+// equal to or greater than b.
+// Example:
+//
+//	l := threadsafe.NewSkipListFunc[time.Time, string](func(a, b time.Time) int {
+//		return a.Compare(b)
+//	})
+func NewSkipListFunc[K, V any](compare func(a, b K) int) *SkipList[K, V] {
+	head := &skipNode[K, V]{next: make([]atomic.Pointer[skipNode[K, V]], skipMaxLevel)}
+	head.fullyLinked.Store(true)
+	return &SkipList[K, V]{compare: compare, head: head}
+}
+
+// randomLevel returns the height of a new node, with a 1 in 4 chance of
+// each additional level.
+func randomLevel() int {
+	level := 1
+	for level < skipMaxLevel && rand.Intn(4) == 0 {
+		level++
+	}
+	return level
+}
+
+// live reports whether n is part of the list.
+func (n *skipNode[K, V]) live() bool {
+	return n.fullyLinked.Load() && !n.marked.Load()
+}
+
+// find fills preds and succs with the nodes around key at every level and
+// returns the highest level at which a node with key was found, or -1.
+func (l *SkipList[K, V]) find(key K, preds, succs *[skipMaxLevel]*skipNode[K, V]) int {
+	found := -1
+	pred := l.head
+	for level := skipMaxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && l.compare(curr.key, key) < 0 {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+		if found == -1 && curr != nil && l.compare(curr.key, key) == 0 {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+	return found
+}
+
+// lookup returns the node holding key, live or not, or nil.
+// Unlike find it stops at the first level where key is found.
+func (l *SkipList[K, V]) lookup(key K) *skipNode[K, V] {
+	pred := l.head
+	for level := skipMaxLevel - 1; level >= 0; level-- {
+		for curr := pred.next[level].Load(); curr != nil; curr = pred.next[level].Load() {
+			c := l.compare(curr.key, key)
+			if c == 0 {
+				return curr
+			}
+			if c > 0 {
+				break
+			}
+			pred = curr
+		}
+	}
+	return nil
+}
+
+// unlockPreds unlocks the distinct predecessors locked at levels up to highest.
+func unlockPreds[K, V any](preds *[skipMaxLevel]*skipNode[K, V], highest int) {
+	for level := 0; level <= highest; level++ {
+		if level == 0 || preds[level] != preds[level-1] {
+			preds[level].mu.Unlock()
+		}
+	}
+}
+
+// Get retrieves the value associated with the key without blocking.
+// It returns the value and a boolean indicating whether the key was found.
+// Example:
+//
+//	value, ok := l.Get(42)
+func (l *SkipList[K, V]) Get(key K) (V, bool) {
+	l.stats.count("Get")
+	if n := l.lookup(key); n != nil && n.live() {
+		return *n.value.Load(), true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains checks if the list contains the specified key without blocking.
+// Example:
+//
+//	contains := l.Contains(42)
+func (l *SkipList[K, V]) Contains(key K) bool {
+	l.stats.count("Contains")
+	n := l.lookup(key)
+	return n != nil && n.live()
+}
+
+// Set sets the value for the given key.
+// Example:
+//
+//	l.Set(42, "answer")
+func (l *SkipList[K, V]) Set(key K, value V) {
+	l.stats.count("Set")
+	topLevel := randomLevel()
+	var preds, succs [skipMaxLevel]*skipNode[K, V]
+	for {
+		if found := l.find(key, &preds, &succs); found != -1 {
+			n := succs[found]
+			for !n.fullyLinked.Load() && !n.marked.Load() {
+				runtime.Gosched()
+			}
+			n.mu.Lock()
+			if n.marked.Load() {
+				// A concurrent Delete is unlinking the key; retry once it is gone.
+				n.mu.Unlock()
+				runtime.Gosched()
+				continue
+			}
+			n.value.Store(&value)
+			n.mu.Unlock()
+			return
+		}
+
+		highest := -1
+		valid := true
+		var prev *skipNode[K, V]
+		for level := 0; valid && level < topLevel; level++ {
+			pred, succ := preds[level], succs[level]
+			if pred != prev {
+				pred.mu.Lock()
+				highest = level
+				prev = pred
+			}
+			valid = !pred.marked.Load() && (succ == nil || !succ.marked.Load()) && pred.next[level].Load() == succ
+		}
+		if !valid {
+			unlockPreds(&preds, highest)
+			continue
+		}
+
+		n := &skipNode[K, V]{key: key, next: make([]atomic.Pointer[skipNode[K, V]], topLevel)}
+		n.value.Store(&value)
+		for level := 0; level < topLevel; level++ {
+			n.next[level].Store(succs[level])
+		}
+		for level := 0; level < topLevel; level++ {
+			preds[level].next[level].Store(n)
+		}
+		n.fullyLinked.Store(true)
+		unlockPreds(&preds, highest)
+		l.length.Add(1)
+		return
+	}
+}
+
+// Delete removes the value associated with the key.
+// Example:
+//
+//	l.Delete(42)
+func (l *SkipList[K, V]) Delete(key K) {
+	l.stats.count("Delete")
+	l.remove(key)
+}
+
+// remove unlinks the node holding key. It returns the node's value and true
+// if this call removed it, or false if the key was absent or another
+// goroutine removed it first.
+func (l *SkipList[K, V]) remove(key K) (V, bool) {
+	var preds, succs [skipMaxLevel]*skipNode[K, V]
+	var victim *skipNode[K, V]
+	var value V
+	for {
+		found := l.find(key, &preds, &succs)
+		if victim == nil {
+			if found == -1 {
+				return value, false
+			}
+			n := succs[found]
+			if !n.fullyLinked.Load() || n.marked.Load() || len(n.next)-1 != found {
+				// The key is still being inserted, or already being removed.
+				return value, false
+			}
+			n.mu.Lock()
+			if n.marked.Load() {
+				n.mu.Unlock()
+				return value, false
+			}
+			n.marked.Store(true)
+			victim = n
+			value = *n.value.Load()
+		}
+
+		highest := -1
+		valid := true
+		var prev *skipNode[K, V]
+		for level := 0; valid && level < len(victim.next); level++ {
+			pred := preds[level]
+			if pred != prev {
+				pred.mu.Lock()
+				highest = level
+				prev = pred
+			}
+			valid = !pred.marked.Load() && pred.next[level].Load() == victim
+		}
+		if !valid {
+			unlockPreds(&preds, highest)
+			continue
+		}
+
+		for level := len(victim.next) - 1; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+		victim.mu.Unlock()
+		unlockPreds(&preds, highest)
+		l.length.Add(-1)
+		return value, true
+	}
+}
+
+// popMin removes and returns the entry with the smallest key.
+func (l *SkipList[K, V]) popMin() (K, V, bool) {
+	for {
+		n := firstLive(l.head.next[0].Load())
+		if n == nil {
+			return l.entry(nil)
+		}
+		if value, ok := l.remove(n.key); ok {
+			return n.key, value, true
+		}
+		// Another goroutine removed the key first; try the next smallest.
+	}
+}
+
+// clear removes every entry present when it starts. Entries set while it
+// runs may remain.
+func (l *SkipList[K, V]) clear() {
+	l.ascend(func(key K, _ V) bool {
+		l.remove(key)
+		return true
+	})
+}
+
+// Length returns the number of key-value pairs in the list.
+// Example:
+//
+//	length := l.Length()
+func (l *SkipList[K, V]) Length() int {
+	l.stats.count("Length")
+	return int(l.length.Load())
+}
+
+// firstLive returns the first live node at or after n on the bottom level.
+func firstLive[K, V any](n *skipNode[K, V]) *skipNode[K, V] {
+	for n != nil && !n.live() {
+		n = n.next[0].Load()
+	}
+	return n
+}
+
+// entry returns the key and value of n, or false if n is nil.
+func (l *SkipList[K, V]) entry(n *skipNode[K, V]) (K, V, bool) {
+	if n == nil {
+		var key K
+		var value V
+		return key, value, false
+	}
+	return n.key, *n.value.Load(), true
+}
+
+// Keys returns the keys in ascending order.
+// Example:
+//
+//	keys := l.Keys()
+func (l *SkipList[K, V]) Keys() []K {
+	l.stats.count("Keys")
+	keys := make([]K, 0, l.length.Load())
+	l.ascend(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values returns the values in ascending order of their keys.
+// Example:
+//
+//	values := l.Values()
+func (l *SkipList[K, V]) Values() []V {
+	l.stats.count("Values")
+	values := make([]V, 0, l.length.Load())
+	l.ascend(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Min returns the entry with the smallest key.
+// The boolean is false if the list is empty.
+// Example:
+//
+//	key, value, ok := l.Min()
+func (l *SkipList[K, V]) Min() (K, V, bool) {
+	l.stats.count("Min")
+	return l.entry(firstLive(l.head.next[0].Load()))
+}
+
+// Max returns the entry with the largest key.
+// The boolean is false if the list is empty.
+// Example:
+//
+//	key, value, ok := l.Max()
+func (l *SkipList[K, V]) Max() (K, V, bool) {
+	l.stats.count("Max")
+	for {
+		x := l.head
+		for level := skipMaxLevel - 1; level >= 0; level-- {
+			for next := x.next[level].Load(); next != nil; next = x.next[level].Load() {
+				x = next
+			}
+		}
+		if x == l.head {
+			return l.entry(nil)
+		}
+		if x.live() {
+			return l.entry(x)
+		}
+		// The last node is being inserted or removed; look again.
+		runtime.Gosched()
+	}
+}
+
+// Floor returns the entry with the largest key less than or equal to key.
+// The boolean is false if there is no such entry.
+// Example:
+//
+//	k, value, ok := l.Floor(42)
+func (l *SkipList[K, V]) Floor(key K) (K, V, bool) {
+	l.stats.count("Floor")
+	var preds, succs [skipMaxLevel]*skipNode[K, V]
+	for {
+		if found := l.find(key, &preds, &succs); found != -1 && succs[found].live() {
+			return l.entry(succs[found])
+		}
+		pred := preds[0]
+		if pred == l.head {
+			return l.entry(nil)
+		}
+		if pred.live() {
+			return l.entry(pred)
+		}
+		// The predecessor is being inserted or removed; look again.
+		runtime.Gosched()
+	}
+}
+
+// Ceiling returns the entry with the smallest key greater than or equal to key.
+// The boolean is false if there is no such entry.
+// Example:
+//
+//	k, value, ok := l.Ceiling(42)
+func (l *SkipList[K, V]) Ceiling(key K) (K, V, bool) {
+	l.stats.count("Ceiling")
+	var preds, succs [skipMaxLevel]*skipNode[K, V]
+	l.find(key, &preds, &succs)
+	return l.entry(firstLive(succs[0]))
+}
+
+// Range calls fn in ascending order for each entry whose key is at least lo
+// and less than hi, until fn returns false. fn may modify the list.
+// Example:
+//
+//	l.Range(10, 20, func(key int, value string) bool {
+//		fmt.Println(key, value)
+//		return true
+//	})
+func (l *SkipList[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	l.stats.count("Range")
+	var preds, succs [skipMaxLevel]*skipNode[K, V]
+	l.find(lo, &preds, &succs)
+	for x := firstLive(succs[0]); x != nil && l.compare(x.key, hi) < 0; x = firstLive(x.next[0].Load()) {
+		if !fn(x.key, *x.value.Load()) {
+			return
+		}
+	}
+}
+
+// Ascend calls fn for each entry in ascending key order until fn returns false.
+// fn may modify the list.
+// Example:
+//
+//	l.Ascend(func(key int, value string) bool {
+//		fmt.Println(key, value)
+//		return true
+//	})
+func (l *SkipList[K, V]) Ascend(fn func(key K, value V) bool) {
+	l.stats.count("Ascend")
+	l.ascend(fn)
+}
+
+// ascend is Ascend without recording a call.
+func (l *SkipList[K, V]) ascend(fn func(key K, value V) bool) {
+	for x := firstLive(l.head.next[0].Load()); x != nil; x = firstLive(x.next[0].Load()) {
+		if !fn(x.key, *x.value.Load()) {
+			return
+		}
+	}
+}
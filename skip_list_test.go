@@ -0,0 +1,207 @@
+package threadsafe
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSkipList(t *testing.T) {
+	l := NewSkipList[int, string]()
+	assert.Equal(t, 0, l.Length())
+	assert.Empty(t, l.Keys())
+	_, _, ok := l.Min()
+	assert.False(t, ok)
+	_, _, ok = l.Max()
+	assert.False(t, ok)
+	_, _, ok = l.Floor(1)
+	assert.False(t, ok)
+	_, _, ok = l.Ceiling(1)
+	assert.False(t, ok)
+}
+
+func TestSkipListSetGetDelete(t *testing.T) {
+	l := NewSkipList[string, int]()
+	l.Set("b", 2)
+	l.Set("c", 3)
+	l.Set("a", 1)
+	l.Set("b", 20)
+	assert.Equal(t, []string{"a", "b", "c"}, l.Keys())
+	assert.Equal(t, []int{1, 20, 3}, l.Values())
+	assert.Equal(t, 3, l.Length())
+
+	value, ok := l.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 20, value)
+	_, ok = l.Get("d")
+	assert.False(t, ok)
+
+	l.Delete("b")
+	l.Delete("missing")
+	assert.False(t, l.Contains("b"))
+	assert.True(t, l.Contains("a"))
+	assert.Equal(t, []string{"a", "c"}, l.Keys())
+	assert.Equal(t, 2, l.Length())
+}
+
+func TestSkipListOrderedQueries(t *testing.T) {
+	l := NewSkipList[int, int]()
+	for i := 0; i < 100; i += 10 {
+		l.Set(i, i*i)
+	}
+	key, _, _ := l.Min()
+	assert.Equal(t, 0, key)
+	key, value, _ := l.Max()
+	assert.Equal(t, 90, key)
+	assert.Equal(t, 8100, value)
+
+	key, _, ok := l.Floor(25)
+	assert.True(t, ok)
+	assert.Equal(t, 20, key)
+	key, _, _ = l.Floor(30)
+	assert.Equal(t, 30, key)
+	_, _, ok = l.Floor(-1)
+	assert.False(t, ok)
+
+	key, _, ok = l.Ceiling(25)
+	assert.True(t, ok)
+	assert.Equal(t, 30, key)
+	key, _, _ = l.Ceiling(30)
+	assert.Equal(t, 30, key)
+	_, _, ok = l.Ceiling(91)
+	assert.False(t, ok)
+
+	var keys []int
+	l.Range(15, 50, func(key, value int) bool {
+		assert.Equal(t, key*key, value)
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []int{20, 30, 40}, keys)
+
+	keys = nil
+	l.Ascend(func(key, value int) bool {
+		keys = append(keys, key)
+		return key < 20
+	})
+	assert.Equal(t, []int{0, 10, 20}, keys)
+}
+
+func TestSkipListFunc(t *testing.T) {
+	l := NewSkipListFunc[string, int](func(a, b string) int {
+		return strings.Compare(b, a)
+	})
+	l.Set("a", 1)
+	l.Set("c", 3)
+	l.Set("b", 2)
+	assert.Equal(t, []string{"c", "b", "a"}, l.Keys())
+}
+
+func TestSkipListRandomized(t *testing.T) {
+	l := NewSkipList[int, int]()
+	ref := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			l.Delete(k)
+			delete(ref, k)
+		} else {
+			l.Set(k, i)
+			ref[k] = i
+		}
+	}
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	assert.Equal(t, keys, l.Keys())
+	assert.Equal(t, len(keys), l.Length())
+	for _, k := range keys {
+		value, ok := l.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, ref[k], value)
+	}
+}
+
+func TestSkipListConcurrentDisjoint(t *testing.T) {
+	l := NewSkipList[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := i*8 + g
+				l.Set(key, g)
+				if i%2 == 1 {
+					l.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 2000, l.Length())
+	keys := l.Keys()
+	assert.Len(t, keys, 2000)
+	assert.True(t, sort.IntsAreSorted(keys))
+	for _, k := range keys {
+		value, ok := l.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, k%8, value)
+	}
+}
+
+func TestSkipListConcurrentStress(t *testing.T) {
+	l := NewSkipList[int, int]()
+	const keys = 64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < 2000; i++ {
+				k := r.Intn(keys)
+				switch r.Intn(6) {
+				case 0, 1:
+					l.Set(k, k)
+				case 2:
+					l.Delete(k)
+				case 3:
+					if v, ok := l.Get(k); ok {
+						assert.Equal(t, k, v)
+					}
+				case 4:
+					prev := -1
+					l.Range(k/2, k+1, func(key, value int) bool {
+						assert.Greater(t, key, prev)
+						assert.Equal(t, key, value)
+						prev = key
+						return true
+					})
+				default:
+					if key, _, ok := l.Floor(k); ok {
+						assert.LessOrEqual(t, key, k)
+					}
+					if key, _, ok := l.Ceiling(k); ok {
+						assert.GreaterOrEqual(t, key, k)
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	got := l.Keys()
+	assert.True(t, sort.IntsAreSorted(got))
+	assert.Equal(t, len(got), l.Length())
+	for i := 1; i < len(got); i++ {
+		assert.NotEqual(t, got[i-1], got[i])
+	}
+}
@@ -2,14 +2,16 @@ package threadsafe
 
 import (
 	"reflect"
-	"sync"
 )
 
 // Slice represents a thread-safe slice.
 // It uses a mutex to ensure that all operations are thread-safe.
 type Slice[T any] struct {
-	data []T
-	mu   sync.RWMutex
+	data  []T
+	mu    rwMutex
+	esc   escapeGuard
+	id    lockID
+	hooks eventHub[sliceEvent[T]]
 }
 
 // NewSlice creates a new thread-safe slice.
@@ -25,9 +27,14 @@ func NewSlice[T any]() *Slice[T] {
 //
 //	slice.Append(10)
 func (s *Slice[T]) Append(value T) {
+	s.mu.count("Append")
+	var events []sliceEvent[T]
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() { s.unlockAndNotify(events) }()
 	s.data = append(s.data, value)
+	if s.hooks.active() {
+		events = []sliceEvent[T]{{kind: sliceInserted, index: len(s.data) - 1, value: value}}
+	}
 }
 
 // Get retrieves the value at the given index.
@@ -36,6 +43,7 @@ func (s *Slice[T]) Append(value T) {
 //
 //	value, ok := slice.Get(2)
 func (s *Slice[T]) Get(index int) (T, bool) {
+	s.mu.count("Get")
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	if index < 0 || index >= len(s.data) {
@@ -51,11 +59,16 @@ func (s *Slice[T]) Get(index int) (T, bool) {
 //
 //	ok := slice.Set(2, 100)
 func (s *Slice[T]) Set(index int, value T) bool {
+	s.mu.count("Set")
+	var events []sliceEvent[T]
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() { s.unlockAndNotify(events) }()
 	if index < 0 || index >= len(s.data) {
 		return false
 	}
+	if s.hooks.active() {
+		events = []sliceEvent[T]{{kind: sliceSet, index: index, oldValue: s.data[index], value: value}}
+	}
 	s.data[index] = value
 	return true
 }
@@ -65,6 +78,7 @@ func (s *Slice[T]) Set(index int, value T) bool {
 //
 //	length := slice.Length()
 func (s *Slice[T]) Length() int {
+	s.mu.count("Length")
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return len(s.data)
@@ -75,6 +89,7 @@ func (s *Slice[T]) Length() int {
 //
 //	values := slice.Values()
 func (s *Slice[T]) Values() []T {
+	s.mu.count("Values")
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	dataCopy := make([]T, len(s.data))
@@ -88,11 +103,16 @@ func (s *Slice[T]) Values() []T {
 //
 //	ok := slice.Remove(2)
 func (s *Slice[T]) Remove(index int) bool {
+	s.mu.count("Remove")
+	var events []sliceEvent[T]
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() { s.unlockAndNotify(events) }()
 	if index < 0 || index >= len(s.data) {
 		return false
 	}
+	if s.hooks.active() {
+		events = []sliceEvent[T]{{kind: sliceRemoved, index: index, value: s.data[index]}}
+	}
 	s.data = append(s.data[:index], s.data[index+1:]...)
 	return true
 }
@@ -102,6 +122,7 @@ func (s *Slice[T]) Remove(index int) bool {
 //
 //	contains := slice.Contains(10)
 func (s *Slice[T]) Contains(value T) bool {
+	s.mu.count("Contains")
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	for _, v := range s.data {
@@ -117,8 +138,16 @@ func (s *Slice[T]) Contains(value T) bool {
 //
 //	slice.Clear()
 func (s *Slice[T]) Clear() {
+	s.mu.count("Clear")
+	var events []sliceEvent[T]
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() { s.unlockAndNotify(events) }()
+	if s.hooks.active() {
+		events = make([]sliceEvent[T], 0, len(s.data))
+		for i := len(s.data) - 1; i >= 0; i-- {
+			events = append(events, sliceEvent[T]{kind: sliceRemoved, index: i, value: s.data[i]})
+		}
+	}
 	s.data = []T{}
 }
 
@@ -128,12 +157,17 @@ func (s *Slice[T]) Clear() {
 //
 //	ok := slice.Insert(2, 10)
 func (s *Slice[T]) Insert(index int, value T) bool {
+	s.mu.count("Insert")
+	var events []sliceEvent[T]
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer func() { s.unlockAndNotify(events) }()
 	if index < 0 || index > len(s.data) {
 		return false
 	}
 	s.data = append(s.data[:index], append([]T{value}, s.data[index:]...)...)
+	if s.hooks.active() {
+		events = []sliceEvent[T]{{kind: sliceInserted, index: index, value: value}}
+	}
 	return true
 }
 
@@ -142,9 +176,57 @@ func (s *Slice[T]) Insert(index int, value T) bool {
 //
 //	copySlice := slice.Copy()
 func (s *Slice[T]) Copy() *Slice[T] {
+	s.mu.count("Copy")
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	dataCopy := make([]T, len(s.data))
 	copy(dataCopy, s.data)
 	return &Slice[T]{data: dataCopy}
 }
+
+// WithLock runs fn with exclusive access to the underlying storage.
+// The slice returned by fn becomes the new contents of the slice.
+// fn must not retain data or call other methods on the slice.
+// Example:
+//
+//	slice.WithLock(func(data []int) []int {
+//		data[0] = data[1] + data[2]
+//		return append(data, data[0])
+//	})
+func (s *Slice[T]) WithLock(fn func(data []T) []T) {
+	s.mu.count("WithLock")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !escapeCheckEnabled() {
+		s.data = fn(s.data)
+		return
+	}
+	s.esc.verify("WithLock")
+	lent := lendSlice(s.data)
+	s.data = lendSlice(fn(lent))
+	retireSlice(&s.esc, lent)
+}
+
+// WithRLock runs fn with shared, read-only access to the underlying storage.
+// fn must not modify or retain data, or call methods that modify the slice.
+// Example:
+//
+//	var sum int
+//	slice.WithRLock(func(data []int) {
+//		for _, v := range data {
+//			sum += v
+//		}
+//	})
+func (s *Slice[T]) WithRLock(fn func(data []T)) {
+	s.mu.count("WithRLock")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !escapeCheckEnabled() {
+		fn(s.data)
+		return
+	}
+	s.esc.verify("WithRLock")
+	lent := lendSlice(s.data)
+	fn(lent)
+	retireSlice(&s.esc, lent)
+}
@@ -0,0 +1,81 @@
+package threadsafe
+
+// sliceEventKind identifies the kind of change a sliceEvent describes.
+type sliceEventKind int
+
+const (
+	sliceInserted sliceEventKind = iota + 1
+	sliceRemoved
+	sliceSet
+)
+
+// sliceEvent describes a change to a Slice.
+type sliceEvent[T any] struct {
+	kind     sliceEventKind
+	index    int
+	oldValue T
+	value    T
+}
+
+// OnInsert registers fn to be called with the index and value of every element
+// added by Append or Insert, and returns a function that unregisters it.
+// Each hook runs on a goroutine of its own, outside the slice's lock, after
+// the change is made; calls happen one at a time in the order of the changes.
+// opts controls buffering as for Map.Watch: by default, changes made while 16
+// are already waiting for the hook are dropped. Hooks may read the slice and
+// unregister themselves, but must not modify the slice.
+// Changes made through WithLock, Atomically or by decoding do not run hooks.
+// Example:
+//
+//	stop := slice.OnInsert(func(index int, value int) {
+//		fmt.Println("inserted", value, "at", index)
+//	})
+//	defer stop()
+func (s *Slice[T]) OnInsert(fn func(index int, value T), opts ...WatchOptions) func() {
+	return s.hooks.subscribeFunc(sliceEventFilter[T](sliceInserted), func(e sliceEvent[T]) {
+		fn(e.index, e.value)
+	}, watchOptions(opts))
+}
+
+// OnRemove registers fn to be called with the index and value of every element
+// removed by Remove or Clear, and returns a function that unregisters it.
+// Clear reports its elements from the last to the first. See OnInsert for
+// when hooks run.
+// Example:
+//
+//	stop := slice.OnRemove(func(index int, value int) {
+//		fmt.Println("removed", value, "from", index)
+//	})
+//	defer stop()
+func (s *Slice[T]) OnRemove(fn func(index int, value T), opts ...WatchOptions) func() {
+	return s.hooks.subscribeFunc(sliceEventFilter[T](sliceRemoved), func(e sliceEvent[T]) {
+		fn(e.index, e.value)
+	}, watchOptions(opts))
+}
+
+// OnSet registers fn to be called with the index, previous value and new value
+// of every element replaced by Set, and returns a function that unregisters it.
+// See OnInsert for when hooks run.
+// Example:
+//
+//	stop := slice.OnSet(func(index int, oldValue, newValue int) {
+//		fmt.Println(index, oldValue, "->", newValue)
+//	})
+//	defer stop()
+func (s *Slice[T]) OnSet(fn func(index int, oldValue, newValue T), opts ...WatchOptions) func() {
+	return s.hooks.subscribeFunc(sliceEventFilter[T](sliceSet), func(e sliceEvent[T]) {
+		fn(e.index, e.oldValue, e.value)
+	}, watchOptions(opts))
+}
+
+// sliceEventFilter matches events of the given kind.
+func sliceEventFilter[T any](kind sliceEventKind) func(sliceEvent[T]) bool {
+	return func(e sliceEvent[T]) bool {
+		return e.kind == kind
+	}
+}
+
+// unlockAndNotify releases s.mu and runs hooks for events.
+func (s *Slice[T]) unlockAndNotify(events []sliceEvent[T]) {
+	s.hooks.unlockAndPublish(s.mu.Unlock, events)
+}
@@ -0,0 +1,129 @@
+package threadsafe
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceHooks(t *testing.T) {
+	slice := NewSlice[string]()
+	var mu sync.Mutex
+	var inserts, removes, sets []string
+	stopInsert := slice.OnInsert(func(index int, value string) {
+		mu.Lock()
+		defer mu.Unlock()
+		inserts = append(inserts, fmt.Sprintf("%d %s", index, value))
+	})
+	slice.OnRemove(func(index int, value string) {
+		mu.Lock()
+		defer mu.Unlock()
+		removes = append(removes, fmt.Sprintf("%d %s", index, value))
+	})
+	slice.OnSet(func(index int, oldValue, newValue string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sets = append(sets, fmt.Sprintf("%d %s %s", index, oldValue, newValue))
+	})
+
+	slice.Append("a")
+	slice.Append("b")
+	slice.Insert(1, "c")
+	slice.Insert(10, "x")
+	slice.Set(0, "d")
+	slice.Remove(1)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(inserts) == 3
+	}, time.Second, time.Millisecond)
+	stopInsert()
+	slice.Append("e")
+	slice.Clear()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(removes) == 4 && len(sets) == 1
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"0 a", "1 b", "1 c"}, inserts)
+	assert.Equal(t, []string{"0 a d"}, sets)
+	assert.Equal(t, []string{"1 c", "2 e", "1 b", "0 d"}, removes)
+}
+
+func TestSliceHookCanReadSlice(t *testing.T) {
+	slice := NewSlice[int]()
+	var mu sync.Mutex
+	var values []int
+	slice.OnInsert(func(index int, value int) {
+		v, _ := slice.Get(index)
+		mu.Lock()
+		defer mu.Unlock()
+		values = append(values, v)
+	})
+	slice.Append(1)
+	slice.Append(2)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return assert.ObjectsAreEqual([]int{1, 2}, values)
+	}, time.Second, time.Millisecond)
+}
+
+func TestSliceHookReadsUnderConcurrentWrites(t *testing.T) {
+	slice := NewSlice[int]()
+	slice.OnInsert(func(index int, value int) {
+		slice.Length()
+		slice.Get(index)
+	})
+	waitOrFail(t, func() {
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					slice.Append(i)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+	assert.Equal(t, 2000, slice.Length())
+}
+
+func TestSliceHooksOrderedUnderConcurrency(t *testing.T) {
+	slice := NewSlice[int]()
+	var mu sync.Mutex
+	var indexes []int
+	slice.OnInsert(func(index int, value int) {
+		mu.Lock()
+		defer mu.Unlock()
+		indexes = append(indexes, index)
+	}, WatchOptions{Overflow: OverflowBlock})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slice.Append(i)
+		}(i)
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(indexes) == 100
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	for i, index := range indexes {
+		assert.Equal(t, i, index)
+	}
+	assert.Len(t, indexes, 100)
+}
@@ -126,3 +126,51 @@ func TestSliceValues(t *testing.T) {
 	assert.Equal(t, 2, values[1])
 	assert.Equal(t, 3, values[2])
 }
+
+func TestSliceWithLock(t *testing.T) {
+	slice := NewSlice[int]()
+	slice.Append(1)
+	slice.Append(2)
+	slice.Append(3)
+	slice.WithLock(func(data []int) []int {
+		data[0] = data[1] + data[2]
+		return append(data, 4)
+	})
+	assert.Equal(t, []int{5, 2, 3, 4}, slice.Values())
+}
+
+func TestSliceWithRLock(t *testing.T) {
+	slice := NewSlice[int]()
+	slice.Append(1)
+	slice.Append(2)
+	var sum int
+	slice.WithRLock(func(data []int) {
+		for _, v := range data {
+			sum += v
+		}
+	})
+	assert.Equal(t, 3, sum)
+}
+
+func TestSliceWithLockEscapeCheck(t *testing.T) {
+	SetEscapeCheck(true)
+	defer SetEscapeCheck(false)
+
+	slice := NewSlice[int]()
+	slice.Append(1)
+	var escaped []int
+	slice.WithLock(func(data []int) []int {
+		escaped = data
+		return append(data, 2)
+	})
+	assert.Equal(t, []int{1, 2}, slice.Values())
+	assert.Equal(t, 0, escaped[0])
+
+	escaped[0] = 42
+	assert.Panics(t, func() {
+		slice.WithRLock(func(data []int) {})
+	})
+	assert.NotPanics(t, func() {
+		slice.WithRLock(func(data []int) {})
+	})
+}
@@ -0,0 +1,84 @@
+package threadsafe
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// SaveTo writes a consistent snapshot of the map to path using codec.
+// The snapshot is written to a temporary file in the same directory, synced
+// to disk and renamed over path, so readers never observe a partial file.
+// Example:
+//
+//	err := m.SaveTo("cache.json", threadsafe.JSONCodec)
+func (m *Map[K, V]) SaveTo(path string, codec Codec) error {
+	data, err := codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// LoadMap reads a map previously written by Map.SaveTo with the same codec.
+// Example:
+//
+//	m, err := threadsafe.LoadMap[string, int]("cache.json", threadsafe.JSONCodec)
+func LoadMap[K comparable, V any](path string, codec Codec) (*Map[K, V], error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := NewMap[K, V]()
+	if err := codec.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// writeFileAtomic replaces the file at path with data via a synced temporary file and a rename.
+// The file keeps the permissions of the one it replaces, or 0644 if it is new.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	perm := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	// CreateTemp uses 0600.
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes directory metadata so a completed rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Some platforms do not support syncing directories; the rename itself has succeeded.
+	_ = d.Sync()
+	return nil
+}
@@ -0,0 +1,66 @@
+package threadsafe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapSaveToLoadMap(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": JSONCodec, "gob": GobCodec} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache")
+			m := NewMap[string, int]()
+			m.Set("a", 1)
+			m.Set("b", 2)
+			assert.NoError(t, m.SaveTo(path, codec))
+
+			loaded, err := LoadMap[string, int](path, codec)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, loaded.Length())
+			value, _ := loaded.Get("b")
+			assert.Equal(t, 2, value)
+		})
+	}
+}
+
+func TestMapSaveToReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	assert.NoError(t, m.SaveTo(path, JSONCodec))
+	m.Delete("a")
+	m.Set("b", 2)
+	assert.NoError(t, m.SaveTo(path, JSONCodec))
+
+	loaded, err := LoadMap[string, int](path, JSONCodec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, loaded.Keys())
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLoadMapMissingFile(t *testing.T) {
+	_, err := LoadMap[string, int](filepath.Join(t.TempDir(), "missing"), JSONCodec)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMapSaveToFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	m := NewMap[string, int]()
+	assert.NoError(t, m.SaveTo(path, JSONCodec))
+	fi, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+
+	assert.NoError(t, os.Chmod(path, 0640))
+	assert.NoError(t, m.SaveTo(path, JSONCodec))
+	fi, err = os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+}
@@ -0,0 +1,200 @@
+package threadsafe
+
+import "cmp"
+
+// SortedMap represents a thread-safe map that keeps its keys sorted.
+// It is built on SkipList, so lookups, inserts and deletes take O(log n)
+// time, readers never block and writers lock only the nodes next to the key
+// they change. On top of SkipList it offers PopMin, Clear and Copy.
+// Like SkipList's, iteration is weakly consistent: Keys, Values, Range,
+// Ascend, Clear and Copy see entries in order without duplicates, but may or
+// may not reflect updates made while they run.
+type SortedMap[K, V any] struct {
+	list  *SkipList[K, V]
+	stats statsHandle
+}
+
+// NewSortedMap creates a new thread-safe map sorted by the natural order of its keys.
+// Example:
+//
+//	m := threadsafe.NewSortedMap[time.Duration, string]()
+func NewSortedMap[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return NewSortedMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewSortedMapFunc creates a new thread-safe map sorted by compare, which
+// returns a negative number, zero or a positive number when a is less than,
+// equal to or greater than b.
+// Example:
+//
+//	m := threadsafe.NewSortedMapFunc[time.Time, string](func(a, b time.Time) int {
+//		return a.Compare(b)
+//	})
+func NewSortedMapFunc[K, V any](compare func(a, b K) int) *SortedMap[K, V] {
+	return &SortedMap[K, V]{list: NewSkipListFunc[K, V](compare)}
+}
+
+// Get retrieves the value associated with the key without blocking.
+// It returns the value and a boolean indicating whether the key was found.
+// Example:
+//
+//	value, ok := m.Get(key)
+func (m *SortedMap[K, V]) Get(key K) (V, bool) {
+	m.stats.count("Get")
+	return m.list.Get(key)
+}
+
+// Set sets the value for the given key.
+// Example:
+//
+//	m.Set(key, 100)
+func (m *SortedMap[K, V]) Set(key K, value V) {
+	m.stats.count("Set")
+	m.list.Set(key, value)
+}
+
+// Delete removes the value associated with the key.
+// Example:
+//
+//	m.Delete(key)
+func (m *SortedMap[K, V]) Delete(key K) {
+	m.stats.count("Delete")
+	m.list.remove(key)
+}
+
+// Contains checks if the map contains the specified key without blocking.
+// Example:
+//
+//	contains := m.Contains(key)
+func (m *SortedMap[K, V]) Contains(key K) bool {
+	m.stats.count("Contains")
+	return m.list.Contains(key)
+}
+
+// Length returns the number of key-value pairs in the map.
+// Example:
+//
+//	length := m.Length()
+func (m *SortedMap[K, V]) Length() int {
+	m.stats.count("Length")
+	return int(m.list.length.Load())
+}
+
+// Keys returns the keys in ascending order.
+// Example:
+//
+//	keys := m.Keys()
+func (m *SortedMap[K, V]) Keys() []K {
+	m.stats.count("Keys")
+	return m.list.Keys()
+}
+
+// Values returns the values in ascending order of their keys.
+// Example:
+//
+//	values := m.Values()
+func (m *SortedMap[K, V]) Values() []V {
+	m.stats.count("Values")
+	return m.list.Values()
+}
+
+// Clear removes every key-value pair present when it starts.
+// Keys set concurrently with Clear may remain.
+// Example:
+//
+//	m.Clear()
+func (m *SortedMap[K, V]) Clear() {
+	m.stats.count("Clear")
+	m.list.clear()
+}
+
+// Copy returns a new thread-safe sorted map with the same entries and ordering.
+// Example:
+//
+//	copyMap := m.Copy()
+func (m *SortedMap[K, V]) Copy() *SortedMap[K, V] {
+	m.stats.count("Copy")
+	c := NewSortedMapFunc[K, V](m.list.compare)
+	m.list.ascend(func(key K, value V) bool {
+		c.list.Set(key, value)
+		return true
+	})
+	return c
+}
+
+// Min returns the entry with the smallest key.
+// The boolean is false if the map is empty.
+// Example:
+//
+//	key, value, ok := m.Min()
+func (m *SortedMap[K, V]) Min() (K, V, bool) {
+	m.stats.count("Min")
+	return m.list.Min()
+}
+
+// Max returns the entry with the largest key.
+// The boolean is false if the map is empty.
+// Example:
+//
+//	key, value, ok := m.Max()
+func (m *SortedMap[K, V]) Max() (K, V, bool) {
+	m.stats.count("Max")
+	return m.list.Max()
+}
+
+// PopMin removes and returns the entry with the smallest key.
+// Concurrent calls never return the same entry.
+// The boolean is false if the map is empty.
+// Example:
+//
+//	key, value, ok := m.PopMin()
+func (m *SortedMap[K, V]) PopMin() (K, V, bool) {
+	m.stats.count("PopMin")
+	return m.list.popMin()
+}
+
+// Floor returns the entry with the largest key less than or equal to key.
+// The boolean is false if there is no such entry.
+// Example:
+//
+//	k, value, ok := m.Floor(key)
+func (m *SortedMap[K, V]) Floor(key K) (K, V, bool) {
+	m.stats.count("Floor")
+	return m.list.Floor(key)
+}
+
+// Ceiling returns the entry with the smallest key greater than or equal to key.
+// The boolean is false if there is no such entry.
+// Example:
+//
+//	k, value, ok := m.Ceiling(key)
+func (m *SortedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	m.stats.count("Ceiling")
+	return m.list.Ceiling(key)
+}
+
+// Range calls fn in ascending order for each entry whose key is at least lo
+// and less than hi, until fn returns false. fn may modify the map.
+// Example:
+//
+//	m.Range(from, to, func(key time.Time, value string) bool {
+//		fmt.Println(key, value)
+//		return true
+//	})
+func (m *SortedMap[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	m.stats.count("Range")
+	m.list.Range(lo, hi, fn)
+}
+
+// Ascend calls fn for each entry in ascending key order until fn returns false.
+// fn may modify the map.
+// Example:
+//
+//	m.Ascend(func(key string, value int) bool {
+//		fmt.Println(key, value)
+//		return true
+//	})
+func (m *SortedMap[K, V]) Ascend(fn func(key K, value V) bool) {
+	m.stats.count("Ascend")
+	m.list.ascend(fn)
+}
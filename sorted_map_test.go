@@ -0,0 +1,251 @@
+package threadsafe
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSortedMap(t *testing.T) {
+	m := NewSortedMap[int, string]()
+	assert.Equal(t, 0, m.Length())
+	assert.Equal(t, []int{}, m.Keys())
+	_, _, ok := m.Min()
+	assert.False(t, ok)
+	_, _, ok = m.Max()
+	assert.False(t, ok)
+	_, _, ok = m.PopMin()
+	assert.False(t, ok)
+}
+
+func TestSortedMapSetGetDelete(t *testing.T) {
+	m := NewSortedMap[string, int]()
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Set("a", 1)
+	m.Set("b", 20)
+	assert.Equal(t, []string{"a", "b", "c"}, m.Keys())
+	assert.Equal(t, []int{1, 20, 3}, m.Values())
+	assert.Equal(t, 3, m.Length())
+
+	value, ok := m.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 20, value)
+	_, ok = m.Get("d")
+	assert.False(t, ok)
+
+	m.Delete("b")
+	m.Delete("missing")
+	assert.False(t, m.Contains("b"))
+	assert.True(t, m.Contains("a"))
+	assert.Equal(t, []string{"a", "c"}, m.Keys())
+	assert.Equal(t, 2, m.Length())
+}
+
+func TestSortedMapMinMaxPopMin(t *testing.T) {
+	m := NewSortedMap[int, string]()
+	for _, k := range []int{5, 1, 9, 3} {
+		m.Set(k, "v")
+	}
+	key, _, ok := m.Min()
+	assert.True(t, ok)
+	assert.Equal(t, 1, key)
+	key, _, ok = m.Max()
+	assert.True(t, ok)
+	assert.Equal(t, 9, key)
+
+	var popped []int
+	for {
+		key, _, ok := m.PopMin()
+		if !ok {
+			break
+		}
+		popped = append(popped, key)
+	}
+	assert.Equal(t, []int{1, 3, 5, 9}, popped)
+	assert.Equal(t, 0, m.Length())
+}
+
+func TestSortedMapFloorCeiling(t *testing.T) {
+	m := NewSortedMap[int, string]()
+	m.Set(10, "ten")
+	m.Set(20, "twenty")
+	m.Set(30, "thirty")
+
+	floors := map[int]int{10: 10, 15: 10, 20: 20, 35: 30}
+	for k, want := range floors {
+		got, _, ok := m.Floor(k)
+		assert.True(t, ok, "Floor(%d)", k)
+		assert.Equal(t, want, got, "Floor(%d)", k)
+	}
+	_, _, ok := m.Floor(5)
+	assert.False(t, ok)
+
+	ceilings := map[int]int{5: 10, 10: 10, 15: 20, 30: 30}
+	for k, want := range ceilings {
+		got, value, ok := m.Ceiling(k)
+		assert.True(t, ok, "Ceiling(%d)", k)
+		assert.Equal(t, want, got, "Ceiling(%d)", k)
+		assert.NotEmpty(t, value)
+	}
+	_, _, ok = m.Ceiling(31)
+	assert.False(t, ok)
+}
+
+func TestSortedMapRange(t *testing.T) {
+	m := NewSortedMap[int, int]()
+	for i := 0; i < 100; i += 10 {
+		m.Set(i, i*i)
+	}
+	var keys []int
+	m.Range(15, 50, func(key, value int) bool {
+		assert.Equal(t, key*key, value)
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []int{20, 30, 40}, keys)
+
+	keys = nil
+	m.Range(20, 90, func(key, value int) bool {
+		keys = append(keys, key)
+		return key < 40
+	})
+	assert.Equal(t, []int{20, 30, 40}, keys)
+
+	keys = nil
+	m.Range(50, 50, func(key, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Empty(t, keys)
+
+	keys = nil
+	m.Ascend(func(key, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, keys)
+}
+
+func TestSortedMapFunc(t *testing.T) {
+	m := NewSortedMapFunc[string, int](func(a, b string) int {
+		return strings.Compare(strings.ToLower(b), strings.ToLower(a))
+	})
+	m.Set("a", 1)
+	m.Set("C", 3)
+	m.Set("b", 2)
+	m.Set("A", 10)
+	assert.Equal(t, []string{"C", "b", "a"}, m.Keys())
+	value, _ := m.Get("a")
+	assert.Equal(t, 10, value)
+
+	c := m.Copy()
+	m.Clear()
+	assert.Equal(t, 0, m.Length())
+	c.Set("d", 4)
+	assert.Equal(t, []string{"d", "C", "b", "a"}, c.Keys())
+}
+
+func TestSortedMapRandomized(t *testing.T) {
+	m := NewSortedMap[int, int]()
+	ref := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			m.Delete(k)
+			delete(ref, k)
+		} else {
+			m.Set(k, i)
+			ref[k] = i
+		}
+	}
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	assert.Equal(t, keys, m.Keys())
+	for _, k := range keys {
+		value, ok := m.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, ref[k], value)
+	}
+}
+
+func TestSortedMapConcurrent(t *testing.T) {
+	m := NewSortedMap[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := g*1000 + i
+				m.Set(key, i)
+				m.Floor(key)
+				m.Ceiling(key)
+				if i%2 == 1 {
+					m.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 800, m.Length())
+	keys := m.Keys()
+	assert.True(t, sort.IntsAreSorted(keys))
+}
+
+func TestSortedMapConcurrentPopMin(t *testing.T) {
+	m := NewSortedMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i*10)
+	}
+	var mu sync.Mutex
+	popped := make(map[int]int)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				key, value, ok := m.PopMin()
+				if !ok {
+					return
+				}
+				mu.Lock()
+				_, dup := popped[key]
+				popped[key] = value
+				mu.Unlock()
+				assert.False(t, dup, "key %d popped twice", key)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, popped, 1000)
+	for key, value := range popped {
+		assert.Equal(t, key*10, value)
+	}
+	assert.Equal(t, 0, m.Length())
+}
+
+func TestSortedMapRangeCanModify(t *testing.T) {
+	m := NewSortedMap[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	m.Range(0, 10, func(key, value int) bool {
+		if key%2 == 0 {
+			m.Delete(key)
+		}
+		return true
+	})
+	assert.Equal(t, []int{1, 3, 5, 7, 9}, m.Keys())
+	m.Clear()
+	assert.Equal(t, 0, m.Length())
+}
@@ -1,15 +1,15 @@
 package threadsafe
 
 import (
-	"sync"
-
 	"github.com/golang-collections/collections/stack"
 )
 
 // Stack is a thread-safe stack.
 type Stack struct {
-	s  *stack.Stack
-	mu sync.Mutex
+	s     *stack.Stack
+	mu    mutex
+	id    lockID
+	added waiters
 }
 
 // NewStack creates a new thread-safe stack.
@@ -21,13 +21,16 @@ func NewStack() *Stack {
 
 // Push adds an element to the stack.
 func (s *Stack) Push(value interface{}) {
+	s.mu.count("Push")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.s.Push(value)
+	s.added.broadcast()
 }
 
 // Pop removes and returns an element from the stack.
 func (s *Stack) Pop() (interface{}, bool) {
+	s.mu.count("Pop")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.s.Len() == 0 {
@@ -38,6 +41,7 @@ func (s *Stack) Pop() (interface{}, bool) {
 
 // Len returns the number of elements in the stack.
 func (s *Stack) Len() int {
+	s.mu.count("Len")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.s.Len()
@@ -48,6 +52,7 @@ func (s *Stack) Len() int {
 //
 //	value, ok := s.Peek()
 func (s *Stack) Peek() (interface{}, bool) {
+	s.mu.count("Peek")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.s.Len() == 0 {
@@ -61,6 +66,7 @@ func (s *Stack) Peek() (interface{}, bool) {
 //
 //	isEmpty := s.IsEmpty()
 func (s *Stack) IsEmpty() bool {
+	s.mu.count("IsEmpty")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.s.Len() == 0
@@ -71,9 +77,10 @@ func (s *Stack) IsEmpty() bool {
 //
 //	s.Clear()
 func (s *Stack) Clear() {
+	s.mu.count("Clear")
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.s = stack.New()
+	s.reset(nil)
 }
 
 // Values returns a slice of all elements in the stack.
@@ -81,9 +88,26 @@ func (s *Stack) Clear() {
 //
 //	values := s.Values()
 func (s *Stack) Values() []interface{} {
+	s.mu.count("Values")
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.values()
+}
+
+// reset replaces the stack's contents with values, the first on top.
+// The caller must hold s.mu.
+func (s *Stack) reset(values []interface{}) {
+	s.s = stack.New()
+	for i := len(values) - 1; i >= 0; i-- {
+		s.s.Push(values[i])
+	}
+	if len(values) > 0 {
+		s.added.broadcast()
+	}
+}
 
+// values returns all elements in the stack from top to bottom. The caller must hold s.mu.
+func (s *Stack) values() []interface{} {
 	// Create a temporary slice to hold the values
 	values := make([]interface{}, 0, s.s.Len())
 
@@ -0,0 +1,509 @@
+package threadsafe
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// histogramBounds are the upper bounds of the lock timing histogram buckets.
+var histogramBounds = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Histogram is a snapshot of a distribution of durations.
+// Counts[i] is the number of observations no greater than Bounds[i] and
+// greater than Bounds[i-1]; the last element of Counts counts observations
+// above the last bound.
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// Stats is a snapshot of the metrics a collection records after EnableStats.
+type Stats struct {
+	// Enabled reports whether the collection is recording metrics.
+	Enabled bool
+	// Ops counts calls per method name, such as "Get" or "Enqueue".
+	Ops map[string]uint64
+	// LockWait is the time spent waiting to acquire the collection's lock.
+	LockWait Histogram
+	// LockHold is the time the collection's exclusive lock was held.
+	LockHold Histogram
+	// Size is the current number of elements.
+	Size int
+}
+
+// histogram accumulates durations with atomic counters.
+type histogram struct {
+	counts [8]uint64
+	count  uint64
+	sum    int64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(histogramBounds) && d > histogramBounds[i] {
+		i++
+	}
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+func (h *histogram) snapshot() Histogram {
+	s := Histogram{
+		Bounds: append([]time.Duration(nil), histogramBounds...),
+		Counts: make([]uint64, len(h.counts)),
+		Count:  atomic.LoadUint64(&h.count),
+		Sum:    time.Duration(atomic.LoadInt64(&h.sum)),
+	}
+	for i := range h.counts {
+		s.Counts[i] = atomic.LoadUint64(&h.counts[i])
+	}
+	return s
+}
+
+// statsRecorder holds the metrics of one collection.
+type statsRecorder struct {
+	ops  sync.Map // method name -> *uint64
+	wait histogram
+	hold histogram
+}
+
+// statsHandle switches metrics recording on and off. When off, every hook
+// costs a single atomic load.
+type statsHandle struct {
+	p atomic.Pointer[statsRecorder]
+}
+
+func (h *statsHandle) enable() {
+	h.p.CompareAndSwap(nil, &statsRecorder{})
+}
+
+func (h *statsHandle) disable() {
+	h.p.Store(nil)
+}
+
+// snapshot returns the recorded metrics without Size.
+func (h *statsHandle) snapshot() Stats {
+	r := h.p.Load()
+	if r == nil {
+		return Stats{}
+	}
+	s := Stats{
+		Enabled:  true,
+		Ops:      make(map[string]uint64),
+		LockWait: r.wait.snapshot(),
+		LockHold: r.hold.snapshot(),
+	}
+	r.ops.Range(func(key, value interface{}) bool {
+		s.Ops[key.(string)] = atomic.LoadUint64(value.(*uint64))
+		return true
+	})
+	return s
+}
+
+// count records a call to the method op.
+func (h *statsHandle) count(op string) {
+	r := h.p.Load()
+	if r == nil {
+		return
+	}
+	n, ok := r.ops.Load(op)
+	if !ok {
+		n, _ = r.ops.LoadOrStore(op, new(uint64))
+	}
+	atomic.AddUint64(n.(*uint64), 1)
+}
+
+// mutex is a sync.Mutex that records lock metrics when stats are enabled
+// and tracks its owner when lock debugging is enabled.
+type mutex struct {
+	sync.Mutex
+	stats    statsHandle
+	acquired time.Time
+	owners   lockOwners
+}
+
+func (mu *mutex) Lock() {
+	if lockDebug.Load() != nil {
+		gid, stack := mu.owners.acquire()
+		mu.lock()
+		mu.owners.acquired(gid, stack, true)
+		return
+	}
+	mu.lock()
+}
+
+func (mu *mutex) lock() {
+	r := mu.stats.p.Load()
+	if r == nil {
+		mu.Mutex.Lock()
+		return
+	}
+	start := time.Now()
+	mu.Mutex.Lock()
+	mu.acquired = time.Now()
+	r.wait.observe(mu.acquired.Sub(start))
+}
+
+func (mu *mutex) Unlock() {
+	mu.owners.release(true)
+	if r := mu.stats.p.Load(); r != nil && !mu.acquired.IsZero() {
+		r.hold.observe(time.Since(mu.acquired))
+	}
+	mu.acquired = time.Time{}
+	mu.Mutex.Unlock()
+}
+
+// lockUntimed is Lock without recording lock metrics, for reading the
+// metrics themselves. Owner tracking still applies, so a Stats call made
+// while the lock is held is reported like any other re-entrant lock.
+func (mu *mutex) lockUntimed() {
+	if lockDebug.Load() != nil {
+		gid, stack := mu.owners.acquire()
+		mu.Mutex.Lock()
+		mu.owners.acquired(gid, stack, true)
+		return
+	}
+	mu.Mutex.Lock()
+}
+
+// count records a call to the method op.
+func (mu *mutex) count(op string) {
+	mu.stats.count(op)
+}
+
+// rwMutex is a sync.RWMutex that records lock metrics when stats are enabled
+// and tracks its owners when lock debugging is enabled.
+// Hold time is recorded for exclusive locks only.
+type rwMutex struct {
+	sync.RWMutex
+	stats    statsHandle
+	acquired time.Time
+	owners   lockOwners
+}
+
+func (mu *rwMutex) Lock() {
+	if lockDebug.Load() != nil {
+		gid, stack := mu.owners.acquire()
+		mu.lock()
+		mu.owners.acquired(gid, stack, true)
+		return
+	}
+	mu.lock()
+}
+
+func (mu *rwMutex) lock() {
+	r := mu.stats.p.Load()
+	if r == nil {
+		mu.RWMutex.Lock()
+		return
+	}
+	start := time.Now()
+	mu.RWMutex.Lock()
+	mu.acquired = time.Now()
+	r.wait.observe(mu.acquired.Sub(start))
+}
+
+func (mu *rwMutex) Unlock() {
+	mu.owners.release(true)
+	if r := mu.stats.p.Load(); r != nil && !mu.acquired.IsZero() {
+		r.hold.observe(time.Since(mu.acquired))
+	}
+	mu.acquired = time.Time{}
+	mu.RWMutex.Unlock()
+}
+
+func (mu *rwMutex) RLock() {
+	if lockDebug.Load() != nil {
+		gid, stack := mu.owners.acquire()
+		mu.rlock()
+		mu.owners.acquired(gid, stack, false)
+		return
+	}
+	mu.rlock()
+}
+
+func (mu *rwMutex) rlock() {
+	r := mu.stats.p.Load()
+	if r == nil {
+		mu.RWMutex.RLock()
+		return
+	}
+	start := time.Now()
+	mu.RWMutex.RLock()
+	r.wait.observe(time.Since(start))
+}
+
+func (mu *rwMutex) RUnlock() {
+	mu.owners.release(false)
+	mu.RWMutex.RUnlock()
+}
+
+// rlockUntimed is RLock without recording lock metrics, for reading the
+// metrics themselves. Owner tracking still applies.
+func (mu *rwMutex) rlockUntimed() {
+	if lockDebug.Load() != nil {
+		gid, stack := mu.owners.acquire()
+		mu.RWMutex.RLock()
+		mu.owners.acquired(gid, stack, false)
+		return
+	}
+	mu.RWMutex.RLock()
+}
+
+// count records a call to the method op.
+func (mu *rwMutex) count(op string) {
+	mu.stats.count(op)
+}
+
+// EnableStats starts recording operation counts and lock timings.
+// Example:
+//
+//	m.EnableStats()
+func (m *Map[K, V]) EnableStats() { m.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (m *Map[K, V]) DisableStats() { m.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+// Example:
+//
+//	fmt.Println(m.Stats().Ops["Get"])
+func (m *Map[K, V]) Stats() Stats {
+	s := m.mu.stats.snapshot()
+	m.mu.rlockUntimed()
+	s.Size = len(m.data)
+	m.mu.RUnlock()
+	return s
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (m *OrderedMap[K, V]) EnableStats() { m.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (m *OrderedMap[K, V]) DisableStats() { m.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (m *OrderedMap[K, V]) Stats() Stats {
+	st := m.mu.stats.snapshot()
+	m.mu.rlockUntimed()
+	st.Size = len(m.data)
+	m.mu.RUnlock()
+	return st
+}
+
+// EnableStats starts recording operation counts.
+// Like SkipList, the map takes no map-wide lock, so no lock timings are recorded.
+func (m *SortedMap[K, V]) EnableStats() { m.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (m *SortedMap[K, V]) DisableStats() { m.stats.disable() }
+
+// Stats returns the operation counts recorded since EnableStats and the current size.
+func (m *SortedMap[K, V]) Stats() Stats {
+	st := m.stats.snapshot()
+	st.Size = int(m.list.length.Load())
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (m *MultiMap[K, V]) EnableStats() { m.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (m *MultiMap[K, V]) DisableStats() { m.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current
+// number of values.
+func (m *MultiMap[K, V]) Stats() Stats {
+	st := m.mu.stats.snapshot()
+	m.mu.rlockUntimed()
+	st.Size = m.count
+	m.mu.RUnlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (m *SetMultiMap[K, V]) EnableStats() { m.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (m *SetMultiMap[K, V]) DisableStats() { m.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current
+// number of values.
+func (m *SetMultiMap[K, V]) Stats() Stats {
+	st := m.mu.stats.snapshot()
+	m.mu.rlockUntimed()
+	st.Size = m.count
+	m.mu.RUnlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (s *Slice[T]) EnableStats() { s.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (s *Slice[T]) DisableStats() { s.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (s *Slice[T]) Stats() Stats {
+	st := s.mu.stats.snapshot()
+	s.mu.rlockUntimed()
+	st.Size = len(s.data)
+	s.mu.RUnlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (a *Array[T]) EnableStats() { a.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (a *Array[T]) DisableStats() { a.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (a *Array[T]) Stats() Stats {
+	st := a.mu.stats.snapshot()
+	a.mu.rlockUntimed()
+	st.Size = len(a.data)
+	a.mu.RUnlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (q *Queue) EnableStats() { q.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (q *Queue) DisableStats() { q.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (q *Queue) Stats() Stats {
+	st := q.mu.stats.snapshot()
+	q.mu.lockUntimed()
+	st.Size = q.len()
+	q.mu.Unlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (s *Stack) EnableStats() { s.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (s *Stack) DisableStats() { s.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (s *Stack) Stats() Stats {
+	st := s.mu.stats.snapshot()
+	s.mu.lockUntimed()
+	st.Size = s.s.Len()
+	s.mu.Unlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+// Lock timings cover writers only, since reads do not lock.
+func (m *COWMap[K, V]) EnableStats() { m.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (m *COWMap[K, V]) DisableStats() { m.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (m *COWMap[K, V]) Stats() Stats {
+	st := m.mu.stats.snapshot()
+	st.Size = len(m.snapshot())
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+// Lock timings cover writers only, since reads do not lock.
+func (s *COWSlice[T]) EnableStats() { s.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (s *COWSlice[T]) DisableStats() { s.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (s *COWSlice[T]) Stats() Stats {
+	st := s.mu.stats.snapshot()
+	st.Size = len(s.load())
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings of the
+// underlying in-memory map.
+func (pm *PersistentMap[K, V]) EnableStats() { pm.m.EnableStats() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (pm *PersistentMap[K, V]) DisableStats() { pm.m.DisableStats() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (pm *PersistentMap[K, V]) Stats() Stats { return pm.m.Stats() }
+
+// EnableStats starts recording operation counts and lock timings.
+func (q *DurableQueue[T]) EnableStats() { q.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (q *DurableQueue[T]) DisableStats() { q.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (q *DurableQueue[T]) Stats() Stats {
+	st := q.mu.stats.snapshot()
+	q.mu.lockUntimed()
+	st.Size = len(q.items)
+	q.mu.Unlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (q *ReliableQueue[T]) EnableStats() { q.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (q *ReliableQueue[T]) DisableStats() { q.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current
+// number of elements, both visible and in flight.
+func (q *ReliableQueue[T]) Stats() Stats {
+	st := q.mu.stats.snapshot()
+	q.mu.lockUntimed()
+	st.Size = len(q.ready) + len(q.inflight)
+	q.mu.Unlock()
+	return st
+}
+
+// EnableStats starts recording operation counts and lock timings.
+func (t *Topic[T]) EnableStats() { t.mu.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (t *Topic[T]) DisableStats() { t.mu.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current
+// number of subscriptions.
+func (t *Topic[T]) Stats() Stats {
+	st := t.mu.stats.snapshot()
+	t.mu.rlockUntimed()
+	st.Size = len(t.subs)
+	t.mu.RUnlock()
+	return st
+}
+
+// EnableStats starts recording operation counts.
+// The list takes no list-wide lock, so no lock timings are recorded.
+func (l *SkipList[K, V]) EnableStats() { l.stats.enable() }
+
+// DisableStats stops recording metrics and discards those recorded so far.
+func (l *SkipList[K, V]) DisableStats() { l.stats.disable() }
+
+// Stats returns the metrics recorded since EnableStats and the current size.
+func (l *SkipList[K, V]) Stats() Stats {
+	st := l.stats.snapshot()
+	st.Size = int(l.length.Load())
+	return st
+}
@@ -0,0 +1,146 @@
+package threadsafe
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsDisabledByDefault(t *testing.T) {
+	m := NewMap[string, int]()
+	m.Set("a", 1)
+	s := m.Stats()
+	assert.False(t, s.Enabled)
+	assert.Nil(t, s.Ops)
+	assert.Equal(t, 1, s.Size)
+}
+
+func TestMapStats(t *testing.T) {
+	m := NewMap[string, int]()
+	m.EnableStats()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Get("a")
+	s := m.Stats()
+	assert.True(t, s.Enabled)
+	assert.Equal(t, uint64(2), s.Ops["Set"])
+	assert.Equal(t, uint64(1), s.Ops["Get"])
+	assert.Equal(t, uint64(3), s.LockWait.Count)
+	assert.Equal(t, uint64(2), s.LockHold.Count)
+	assert.Len(t, s.LockWait.Counts, len(s.LockWait.Bounds)+1)
+	assert.Equal(t, 2, s.Size)
+
+	m.DisableStats()
+	m.Get("a")
+	assert.False(t, m.Stats().Enabled)
+}
+
+func TestStatsLockHoldTime(t *testing.T) {
+	m := NewMap[string, int]()
+	m.EnableStats()
+	m.WithLock(func(data map[string]int) {
+		time.Sleep(2 * time.Millisecond)
+	})
+	s := m.Stats()
+	assert.Equal(t, uint64(1), s.LockHold.Count)
+	assert.GreaterOrEqual(t, s.LockHold.Sum, 2*time.Millisecond)
+	var slow uint64
+	for i, bound := range s.LockHold.Bounds {
+		if bound >= time.Millisecond {
+			slow += s.LockHold.Counts[i]
+		}
+	}
+	slow += s.LockHold.Counts[len(s.LockHold.Bounds)]
+	assert.Equal(t, uint64(1), slow)
+}
+
+func TestStatsAllCollections(t *testing.T) {
+	slice := NewSlice[int]()
+	arr := NewArray[int](2)
+	queue := NewQueue()
+	stack := NewStack()
+	cowMap := NewCOWMap[string, int]()
+	cowSlice := NewCOWSlice[int]()
+	slice.EnableStats()
+	arr.EnableStats()
+	queue.EnableStats()
+	stack.EnableStats()
+	cowMap.EnableStats()
+	cowSlice.EnableStats()
+
+	slice.Append(1)
+	arr.Set(0, 1)
+	queue.Enqueue(1)
+	stack.Push(1)
+	stack.Pop()
+	cowMap.Set("a", 1)
+	cowSlice.Append(1)
+	cowSlice.Get(0)
+
+	assert.Equal(t, uint64(1), slice.Stats().Ops["Append"])
+	assert.Equal(t, 1, slice.Stats().Size)
+	assert.Equal(t, uint64(1), arr.Stats().Ops["Set"])
+	assert.Equal(t, 2, arr.Stats().Size)
+	assert.Equal(t, uint64(1), queue.Stats().Ops["Enqueue"])
+	assert.Equal(t, 1, queue.Stats().Size)
+	assert.Equal(t, uint64(1), stack.Stats().Ops["Pop"])
+	assert.Equal(t, 0, stack.Stats().Size)
+	assert.Equal(t, uint64(1), cowMap.Stats().Ops["Set"])
+	assert.Equal(t, 1, cowMap.Stats().Size)
+	assert.Equal(t, map[string]uint64{"Append": 1, "Get": 1}, cowSlice.Stats().Ops)
+}
+
+func TestStatsQueuesTopicAndSkipList(t *testing.T) {
+	durable, err := OpenDurableQueue[int](t.TempDir(), DurableQueueOptions{NoSync: true})
+	assert.NoError(t, err)
+	defer durable.Close()
+	reliable := NewReliableQueue(ReliableQueueOptions[int]{})
+	topic := NewTopic[int]()
+	list := NewSkipList[int, int]()
+	durable.EnableStats()
+	reliable.EnableStats()
+	topic.EnableStats()
+	list.EnableStats()
+
+	assert.NoError(t, durable.Enqueue(1))
+	reliable.Enqueue(1)
+	reliable.Enqueue(2)
+	reliable.Receive()
+	topic.Subscribe(1, OverflowDrop)
+	topic.Publish(1)
+	list.Set(1, 1)
+	list.Keys()
+
+	assert.Equal(t, uint64(1), durable.Stats().Ops["Enqueue"])
+	assert.Equal(t, 1, durable.Stats().Size)
+	assert.Equal(t, uint64(1), durable.Stats().LockWait.Count)
+	assert.Equal(t, uint64(2), reliable.Stats().Ops["Enqueue"])
+	assert.Equal(t, 2, reliable.Stats().Size)
+	assert.Equal(t, map[string]uint64{"Subscribe": 1, "Publish": 1}, topic.Stats().Ops)
+	assert.Equal(t, 1, topic.Stats().Size)
+	assert.Equal(t, map[string]uint64{"Set": 1, "Keys": 1}, list.Stats().Ops)
+	assert.Equal(t, 1, list.Stats().Size)
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	m := NewMap[int, int]()
+	m.EnableStats()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Set(i, j)
+				m.Get(i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	s := m.Stats()
+	assert.Equal(t, uint64(800), s.Ops["Set"])
+	assert.Equal(t, uint64(800), s.Ops["Get"])
+	assert.Equal(t, uint64(800), s.LockHold.Count)
+}
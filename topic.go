@@ -0,0 +1,208 @@
+package threadsafe
+
+import (
+	"context"
+	"sync"
+)
+
+// Topic is a thread-safe publish/subscribe broadcast.
+// Every published value is delivered to each current subscription's own
+// bounded queue; a full queue is handled by the subscription's OverflowPolicy.
+type Topic[T any] struct {
+	mu   rwMutex
+	subs []*Subscription[T]
+}
+
+// NewTopic creates a new topic.
+// Example:
+//
+//	t := threadsafe.NewTopic[string]()
+func NewTopic[T any]() *Topic[T] {
+	return &Topic[T]{}
+}
+
+// Subscription receives the values published to a Topic after it was created.
+type Subscription[T any] struct {
+	mu       sync.Mutex
+	items    []T
+	size     int
+	overflow OverflowPolicy
+	closed   bool
+	// ready is signalled when items are added or the subscription is closed.
+	ready chan struct{}
+	// space is signalled when items are removed or the subscription is closed.
+	space chan struct{}
+}
+
+// Subscribe creates a subscription whose queue holds up to bufferSize values.
+// overflow decides what Publish does when the queue is full: OverflowDrop
+// discards the new value, OverflowBlock waits for room, and OverflowBuffer
+// lets the queue grow beyond bufferSize.
+// Example:
+//
+//	sub := t.Subscribe(100, threadsafe.OverflowDrop)
+//	defer t.Unsubscribe(sub)
+func (t *Topic[T]) Subscribe(bufferSize int, overflow OverflowPolicy) *Subscription[T] {
+	t.mu.count("Subscribe")
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	s := &Subscription[T]{
+		size:     bufferSize,
+		overflow: overflow,
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subs = append(t.subs, s)
+	return s
+}
+
+// Unsubscribe removes s from the topic. Values already queued can still be
+// read; after that Next returns ErrClosed. Unsubscribing more than once has
+// no effect.
+// Example:
+//
+//	t.Unsubscribe(sub)
+func (t *Topic[T]) Unsubscribe(s *Subscription[T]) {
+	t.mu.count("Unsubscribe")
+	t.mu.Lock()
+	for i, sub := range t.subs {
+		if sub == s {
+			t.subs = append(t.subs[:i], t.subs[i+1:]...)
+			break
+		}
+	}
+	t.mu.Unlock()
+	s.close()
+}
+
+// Publish delivers value to every subscription.
+// It blocks only while a subscription using OverflowBlock is full.
+// Example:
+//
+//	t.Publish("hello")
+func (t *Topic[T]) Publish(value T) {
+	t.mu.count("Publish")
+	t.mu.RLock()
+	subs := make([]*Subscription[T], len(t.subs))
+	copy(subs, t.subs)
+	t.mu.RUnlock()
+	for _, s := range subs {
+		s.push(value)
+	}
+}
+
+// Subscribers returns the number of current subscriptions.
+// Example:
+//
+//	n := t.Subscribers()
+func (t *Topic[T]) Subscribers() int {
+	t.mu.count("Subscribers")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.subs)
+}
+
+// push adds value to the subscription's queue according to its overflow policy.
+func (s *Subscription[T]) push(value T) {
+	s.mu.Lock()
+	for !s.closed && len(s.items) >= s.size && s.overflow == OverflowBlock {
+		s.mu.Unlock()
+		<-s.space
+		s.mu.Lock()
+	}
+	defer s.mu.Unlock()
+	if s.closed || (len(s.items) >= s.size && s.overflow == OverflowDrop) {
+		return
+	}
+	s.items = append(s.items, value)
+	signal(s.ready)
+}
+
+// close marks the subscription closed and wakes all waiters.
+// Closing an already closed subscription has no effect.
+func (s *Subscription[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ready)
+	close(s.space)
+}
+
+// signal wakes one waiter on ch without blocking.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// TryNext removes and returns the next value without blocking.
+// The boolean is false if no value is queued.
+// Example:
+//
+//	value, ok := sub.TryNext()
+func (s *Subscription[T]) TryNext() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pop()
+}
+
+// pop removes the next value. The caller must hold s.mu.
+func (s *Subscription[T]) pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	value := s.items[0]
+	s.items[0] = zero
+	s.items = s.items[1:]
+	if !s.closed {
+		signal(s.space)
+		if len(s.items) > 0 {
+			signal(s.ready)
+		}
+	}
+	return value, true
+}
+
+// Next removes and returns the next value, waiting until one is published.
+// It returns ctx.Err() if ctx is done first, and ErrClosed once the
+// subscription has been unsubscribed and drained.
+// Example:
+//
+//	value, err := sub.Next(ctx)
+func (s *Subscription[T]) Next(ctx context.Context) (T, error) {
+	for {
+		s.mu.Lock()
+		value, ok := s.pop()
+		closed := s.closed
+		s.mu.Unlock()
+		if ok {
+			return value, nil
+		}
+		if closed {
+			return value, ErrClosed
+		}
+		select {
+		case <-s.ready:
+		case <-ctx.Done():
+			return value, ctx.Err()
+		}
+	}
+}
+
+// Len returns the number of values waiting in the subscription's queue.
+// Example:
+//
+//	length := sub.Len()
+func (s *Subscription[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
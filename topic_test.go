@@ -0,0 +1,153 @@
+package threadsafe
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicBroadcast(t *testing.T) {
+	topic := NewTopic[string]()
+	a := topic.Subscribe(10, OverflowDrop)
+	b := topic.Subscribe(10, OverflowDrop)
+	assert.Equal(t, 2, topic.Subscribers())
+	topic.Publish("hello")
+	topic.Publish("world")
+
+	ctx := context.Background()
+	for _, sub := range []*Subscription[string]{a, b} {
+		value, err := sub.Next(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", value)
+		value, err = sub.Next(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "world", value)
+	}
+}
+
+func TestTopicLateSubscriber(t *testing.T) {
+	topic := NewTopic[int]()
+	topic.Publish(1)
+	sub := topic.Subscribe(10, OverflowDrop)
+	_, ok := sub.TryNext()
+	assert.False(t, ok)
+}
+
+func TestTopicOverflowDrop(t *testing.T) {
+	topic := NewTopic[int]()
+	sub := topic.Subscribe(2, OverflowDrop)
+	for i := 0; i < 5; i++ {
+		topic.Publish(i)
+	}
+	assert.Equal(t, 2, sub.Len())
+	value, _ := sub.TryNext()
+	assert.Equal(t, 0, value)
+}
+
+func TestTopicOverflowBuffer(t *testing.T) {
+	topic := NewTopic[int]()
+	sub := topic.Subscribe(2, OverflowBuffer)
+	for i := 0; i < 5; i++ {
+		topic.Publish(i)
+	}
+	assert.Equal(t, 5, sub.Len())
+}
+
+func TestTopicOverflowBlock(t *testing.T) {
+	topic := NewTopic[int]()
+	sub := topic.Subscribe(1, OverflowBlock)
+	topic.Publish(1)
+	done := make(chan struct{})
+	go func() {
+		topic.Publish(2)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("publisher did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+	value, err := sub.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher still blocked")
+	}
+	value, _ = sub.TryNext()
+	assert.Equal(t, 2, value)
+}
+
+func TestTopicUnsubscribeUnblocksPublisher(t *testing.T) {
+	topic := NewTopic[int]()
+	sub := topic.Subscribe(1, OverflowBlock)
+	topic.Publish(1)
+	done := make(chan struct{})
+	go func() {
+		topic.Publish(2)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	topic.Unsubscribe(sub)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher still blocked")
+	}
+	value, err := sub.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	_, err = sub.Next(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Equal(t, 0, topic.Subscribers())
+}
+
+func TestTopicNextContext(t *testing.T) {
+	topic := NewTopic[int]()
+	sub := topic.Subscribe(1, OverflowDrop)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := sub.Next(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTopicConcurrent(t *testing.T) {
+	topic := NewTopic[int]()
+	const subscribers, messages = 4, 200
+	var wg sync.WaitGroup
+	results := make([][]int, subscribers)
+	for i := 0; i < subscribers; i++ {
+		sub := topic.Subscribe(8, OverflowBlock)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				value, err := sub.Next(context.Background())
+				assert.NoError(t, err)
+				results[i] = append(results[i], value)
+			}
+		}(i)
+	}
+	for j := 0; j < messages; j++ {
+		topic.Publish(j)
+	}
+	wg.Wait()
+	for _, r := range results {
+		assert.Len(t, r, messages)
+		for j, value := range r {
+			assert.Equal(t, j, value)
+		}
+	}
+}
+
+func TestTopicUnsubscribeTwice(t *testing.T) {
+	topic := NewTopic[int]()
+	sub := topic.Subscribe(1, OverflowDrop)
+	topic.Unsubscribe(sub)
+	assert.NotPanics(t, func() { topic.Unsubscribe(sub) })
+	assert.Equal(t, 0, topic.Subscribers())
+}
@@ -0,0 +1,456 @@
+package threadsafe
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// Lockable is a collection that can take part in Atomically.
+// Array, Slice, Map, Queue and Stack implement Lockable.
+type Lockable interface {
+	lockID() uint64
+	lock()
+	unlock()
+}
+
+// nextLockID is the last identifier handed out to a collection.
+var nextLockID uint64
+
+// lockID lazily assigns a collection a process-wide identifier
+// that defines the order in which Atomically acquires locks.
+type lockID struct {
+	v uint64
+}
+
+func (id *lockID) get() uint64 {
+	if v := atomic.LoadUint64(&id.v); v != 0 {
+		return v
+	}
+	atomic.CompareAndSwapUint64(&id.v, 0, atomic.AddUint64(&nextLockID, 1))
+	return atomic.LoadUint64(&id.v)
+}
+
+// Txn is a transaction spanning several collections, created by Atomically.
+// Use TxnMap, TxnSlice, TxnArray, TxnQueue and TxnStack to access the
+// participating collections. A Txn must not be used after the callback
+// that received it returns.
+type Txn struct {
+	locked map[Lockable]bool
+	views  map[Lockable]txnView
+	order  []txnView
+}
+
+// txnView is the view of one collection within a Txn. Views change their
+// collection in place and keep what is needed to undo the changes, so a
+// transaction costs time proportional to the operations it performs rather
+// than to the size of the collections.
+type txnView interface {
+	// commit finishes the changes once fn has returned nil.
+	commit()
+	// rollback undoes every change made through the view.
+	rollback()
+}
+
+// Atomically runs fn while holding the locks of all given collections.
+// Locks are acquired in a stable global order, so concurrent calls over
+// overlapping collections cannot deadlock. Changes made through tx are
+// kept only if fn returns nil; if fn returns an error or panics, they are
+// undone and every collection is left unchanged. fn must not call methods on
+// the participating collections directly. Atomically returns the error from fn.
+// Example:
+//
+//	err := threadsafe.Atomically(func(tx *threadsafe.Txn) error {
+//		job, ok := threadsafe.TxnQueue(tx, pending).Dequeue()
+//		if !ok {
+//			return errors.New("no pending jobs")
+//		}
+//		threadsafe.TxnMap(tx, running).Set(job.(string), time.Now())
+//		return nil
+//	}, pending, running)
+func Atomically(fn func(tx *Txn) error, colls ...Lockable) error {
+	ordered := make([]Lockable, 0, len(colls))
+	tx := &Txn{
+		locked: make(map[Lockable]bool, len(colls)),
+		views:  make(map[Lockable]txnView, len(colls)),
+	}
+	for _, c := range colls {
+		if !tx.locked[c] {
+			tx.locked[c] = true
+			ordered = append(ordered, c)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].lockID() < ordered[j].lockID()
+	})
+	for _, c := range ordered {
+		c.lock()
+	}
+	defer func() {
+		for i := len(ordered) - 1; i >= 0; i-- {
+			ordered[i].unlock()
+		}
+	}()
+	committed := false
+	defer func() {
+		if !committed {
+			for i := len(tx.order) - 1; i >= 0; i-- {
+				tx.order[i].rollback()
+			}
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	for _, v := range tx.order {
+		v.commit()
+	}
+	return nil
+}
+
+// view returns the cached view for c, creating it with create on first use.
+func (tx *Txn) view(c Lockable, create func() txnView) txnView {
+	if !tx.locked[c] {
+		panic("threadsafe: collection is not part of the transaction")
+	}
+	if v, ok := tx.views[c]; ok {
+		return v
+	}
+	v := create()
+	tx.views[c] = v
+	tx.order = append(tx.order, v)
+	return v
+}
+
+// TxnMap returns the transactional view of m within tx.
+// m must have been passed to Atomically.
+// Example:
+//
+//	threadsafe.TxnMap(tx, m).Set("key", 100)
+func TxnMap[K comparable, V any](tx *Txn, m *Map[K, V]) *MapTx[K, V] {
+	return tx.view(m, func() txnView {
+		return &MapTx[K, V]{m: m, changes: make(map[K]txChange[V])}
+	}).(*MapTx[K, V])
+}
+
+// TxnSlice returns the transactional view of s within tx.
+// s must have been passed to Atomically.
+// Example:
+//
+//	threadsafe.TxnSlice(tx, s).Append(10)
+func TxnSlice[T any](tx *Txn, s *Slice[T]) *SliceTx[T] {
+	return tx.view(s, func() txnView {
+		return &SliceTx[T]{data: &s.data}
+	}).(*SliceTx[T])
+}
+
+// TxnArray returns the transactional view of a within tx.
+// a must have been passed to Atomically.
+// Example:
+//
+//	threadsafe.TxnArray(tx, arr).Set(2, 100)
+func TxnArray[T any](tx *Txn, a *Array[T]) *SliceTx[T] {
+	return tx.view(a, func() txnView {
+		return &SliceTx[T]{data: &a.data}
+	}).(*SliceTx[T])
+}
+
+// TxnQueue returns the transactional view of q within tx.
+// q must have been passed to Atomically.
+// Example:
+//
+//	value, ok := threadsafe.TxnQueue(tx, q).Dequeue()
+func TxnQueue(tx *Txn, q *Queue) *QueueTx {
+	return tx.view(q, func() txnView {
+		return &QueueTx{q: q}
+	}).(*QueueTx)
+}
+
+// TxnStack returns the transactional view of s within tx.
+// s must have been passed to Atomically.
+// Example:
+//
+//	value, ok := threadsafe.TxnStack(tx, s).Pop()
+func TxnStack(tx *Txn, s *Stack) *StackTx {
+	return tx.view(s, func() txnView {
+		return &StackTx{s: s}
+	}).(*StackTx)
+}
+
+// SliceTx is a transactional view of a Slice or Array.
+// It changes the collection in place and records how to undo each change.
+type SliceTx[T any] struct {
+	data *[]T
+	undo []func()
+}
+
+func (stx *SliceTx[T]) commit() {}
+
+func (stx *SliceTx[T]) rollback() {
+	for i := len(stx.undo) - 1; i >= 0; i-- {
+		stx.undo[i]()
+	}
+}
+
+// Get retrieves the value at the given index.
+// It returns the value and a boolean indicating whether the index was valid.
+// Example:
+//
+//	value, ok := stx.Get(2)
+func (stx *SliceTx[T]) Get(index int) (T, bool) {
+	if index < 0 || index >= len(*stx.data) {
+		var zero T
+		return zero, false
+	}
+	return (*stx.data)[index], true
+}
+
+// Set sets the value at the given index.
+// It returns a boolean indicating whether the operation was successful.
+// Example:
+//
+//	ok := stx.Set(2, 100)
+func (stx *SliceTx[T]) Set(index int, value T) bool {
+	if index < 0 || index >= len(*stx.data) {
+		return false
+	}
+	old := (*stx.data)[index]
+	(*stx.data)[index] = value
+	stx.undo = append(stx.undo, func() { (*stx.data)[index] = old })
+	return true
+}
+
+// Append appends a value.
+// Example:
+//
+//	stx.Append(10)
+func (stx *SliceTx[T]) Append(value T) {
+	n := len(*stx.data)
+	*stx.data = append(*stx.data, value)
+	stx.undo = append(stx.undo, func() { *stx.data = removeAt(*stx.data, n) })
+}
+
+// Remove removes the element at the given index.
+// It returns a boolean indicating whether the operation was successful.
+// Example:
+//
+//	ok := stx.Remove(2)
+func (stx *SliceTx[T]) Remove(index int) bool {
+	if index < 0 || index >= len(*stx.data) {
+		return false
+	}
+	old := (*stx.data)[index]
+	*stx.data = removeAt(*stx.data, index)
+	stx.undo = append(stx.undo, func() { *stx.data = insertAt(*stx.data, index, old) })
+	return true
+}
+
+// Insert inserts a value at the specified index.
+// It returns a boolean indicating whether the operation was successful.
+// Example:
+//
+//	ok := stx.Insert(2, 10)
+func (stx *SliceTx[T]) Insert(index int, value T) bool {
+	if index < 0 || index > len(*stx.data) {
+		return false
+	}
+	*stx.data = insertAt(*stx.data, index, value)
+	stx.undo = append(stx.undo, func() { *stx.data = removeAt(*stx.data, index) })
+	return true
+}
+
+// Length returns the number of elements.
+// Example:
+//
+//	length := stx.Length()
+func (stx *SliceTx[T]) Length() int {
+	return len(*stx.data)
+}
+
+// insertAt inserts value at index in place, growing data by one element.
+func insertAt[T any](data []T, index int, value T) []T {
+	var zero T
+	data = append(data, zero)
+	copy(data[index+1:], data[index:])
+	data[index] = value
+	return data
+}
+
+// removeAt removes the element at index in place and clears the freed slot.
+func removeAt[T any](data []T, index int) []T {
+	copy(data[index:], data[index+1:])
+	var zero T
+	data[len(data)-1] = zero
+	return data[:len(data)-1]
+}
+
+// QueueTx is a transactional view of a Queue.
+// Dequeued elements are taken from the queue at once and put back at its
+// front on rollback; enqueued elements are held back until commit, since
+// the queue cannot remove elements from its back.
+type QueueTx struct {
+	q        *Queue
+	removed  []interface{}
+	enqueued []interface{}
+}
+
+func (qtx *QueueTx) commit() {
+	for _, value := range qtx.enqueued {
+		qtx.q.q.Enqueue(value)
+	}
+	if len(qtx.enqueued) > 0 {
+		qtx.q.added.broadcast()
+	}
+}
+
+func (qtx *QueueTx) rollback() {
+	for i := len(qtx.removed) - 1; i >= 0; i-- {
+		qtx.q.front = append(qtx.q.front, qtx.removed[i])
+	}
+}
+
+// Enqueue adds an element to the queue.
+// Example:
+//
+//	qtx.Enqueue(10)
+func (qtx *QueueTx) Enqueue(value interface{}) {
+	qtx.enqueued = append(qtx.enqueued, value)
+}
+
+// Dequeue removes and returns an element from the queue.
+// Example:
+//
+//	value, ok := qtx.Dequeue()
+func (qtx *QueueTx) Dequeue() (interface{}, bool) {
+	if qtx.q.len() > 0 {
+		value := qtx.q.dequeue()
+		qtx.removed = append(qtx.removed, value)
+		return value, true
+	}
+	if len(qtx.enqueued) == 0 {
+		return nil, false
+	}
+	value := qtx.enqueued[0]
+	qtx.enqueued[0] = nil
+	qtx.enqueued = qtx.enqueued[1:]
+	return value, true
+}
+
+// Peek returns the element at the front of the queue without removing it.
+// Example:
+//
+//	value, ok := qtx.Peek()
+func (qtx *QueueTx) Peek() (interface{}, bool) {
+	if n := len(qtx.q.front); n > 0 {
+		return qtx.q.front[n-1], true
+	}
+	if qtx.q.q.Len() > 0 {
+		return qtx.q.q.Peek(), true
+	}
+	if len(qtx.enqueued) == 0 {
+		return nil, false
+	}
+	return qtx.enqueued[0], true
+}
+
+// Len returns the number of elements in the queue.
+// Example:
+//
+//	length := qtx.Len()
+func (qtx *QueueTx) Len() int {
+	return qtx.q.len() + len(qtx.enqueued)
+}
+
+// StackTx is a transactional view of a Stack.
+// It changes the stack in place and records each push and pop so that they
+// can be undone.
+type StackTx struct {
+	s   *Stack
+	log []stackTxOp
+}
+
+// stackTxOp is a push or pop made through a StackTx.
+type stackTxOp struct {
+	push  bool
+	value interface{}
+}
+
+func (stx *StackTx) commit() {
+	for _, op := range stx.log {
+		if op.push {
+			stx.s.added.broadcast()
+			return
+		}
+	}
+}
+
+func (stx *StackTx) rollback() {
+	for i := len(stx.log) - 1; i >= 0; i-- {
+		if stx.log[i].push {
+			stx.s.s.Pop()
+		} else {
+			stx.s.s.Push(stx.log[i].value)
+		}
+	}
+}
+
+// Push adds an element to the stack.
+// Example:
+//
+//	stx.Push(10)
+func (stx *StackTx) Push(value interface{}) {
+	stx.s.s.Push(value)
+	stx.log = append(stx.log, stackTxOp{push: true})
+}
+
+// Pop removes and returns an element from the stack.
+// Example:
+//
+//	value, ok := stx.Pop()
+func (stx *StackTx) Pop() (interface{}, bool) {
+	if stx.s.s.Len() == 0 {
+		return nil, false
+	}
+	value := stx.s.s.Pop()
+	stx.log = append(stx.log, stackTxOp{value: value})
+	return value, true
+}
+
+// Peek returns the element at the top of the stack without removing it.
+// Example:
+//
+//	value, ok := stx.Peek()
+func (stx *StackTx) Peek() (interface{}, bool) {
+	if stx.s.s.Len() == 0 {
+		return nil, false
+	}
+	return stx.s.s.Peek(), true
+}
+
+// Len returns the number of elements in the stack.
+// Example:
+//
+//	length := stx.Len()
+func (stx *StackTx) Len() int {
+	return stx.s.s.Len()
+}
+
+func (m *Map[K, V]) lockID() uint64 { return m.id.get() }
+func (m *Map[K, V]) lock()          { m.mu.Lock() }
+func (m *Map[K, V]) unlock()        { m.mu.Unlock() }
+
+func (s *Slice[T]) lockID() uint64 { return s.id.get() }
+func (s *Slice[T]) lock()          { s.mu.Lock() }
+func (s *Slice[T]) unlock()        { s.mu.Unlock() }
+
+func (a *Array[T]) lockID() uint64 { return a.id.get() }
+func (a *Array[T]) lock()          { a.mu.Lock() }
+func (a *Array[T]) unlock()        { a.mu.Unlock() }
+
+func (q *Queue) lockID() uint64 { return q.id.get() }
+func (q *Queue) lock()          { q.mu.Lock() }
+func (q *Queue) unlock()        { q.mu.Unlock() }
+
+func (s *Stack) lockID() uint64 { return s.id.get() }
+func (s *Stack) lock()          { s.mu.Lock() }
+func (s *Stack) unlock()        { s.mu.Unlock() }
@@ -0,0 +1,176 @@
+package threadsafe
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicallyQueueToMap(t *testing.T) {
+	pending := NewQueue()
+	pending.Enqueue("job1")
+	pending.Enqueue("job2")
+	running := NewMap[string, bool]()
+
+	err := Atomically(func(tx *Txn) error {
+		job, ok := TxnQueue(tx, pending).Dequeue()
+		if !ok {
+			return errors.New("no pending jobs")
+		}
+		TxnMap(tx, running).Set(job.(string), true)
+		return nil
+	}, pending, running)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"job2"}, pending.Values())
+	assert.True(t, running.Contains("job1"))
+}
+
+func TestAtomicallyRollback(t *testing.T) {
+	src := NewSlice[int]()
+	src.Append(1)
+	src.Append(2)
+	dst := NewSlice[int]()
+	stack := NewStack()
+	stack.Push("a")
+
+	err := Atomically(func(tx *Txn) error {
+		value, _ := TxnSlice(tx, src).Get(0)
+		TxnSlice(tx, src).Remove(0)
+		TxnSlice(tx, dst).Append(value)
+		TxnStack(tx, stack).Pop()
+		return errors.New("abort")
+	}, src, dst, stack)
+
+	assert.EqualError(t, err, "abort")
+	assert.Equal(t, []int{1, 2}, src.Values())
+	assert.Equal(t, 0, dst.Length())
+	assert.Equal(t, 1, stack.Len())
+}
+
+func TestAtomicallyArrayAndStack(t *testing.T) {
+	arr := NewArray[int](2)
+	stack := NewStack()
+	stack.Push(1)
+	stack.Push(2)
+
+	err := Atomically(func(tx *Txn) error {
+		stx := TxnStack(tx, stack)
+		top, _ := stx.Pop()
+		stx.Push(3)
+		TxnArray(tx, arr).Set(0, top.(int))
+		return nil
+	}, arr, stack)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 0}, arr.Values())
+	assert.Equal(t, []interface{}{3, 1}, stack.Values())
+}
+
+func TestAtomicallyUnlistedCollectionPanics(t *testing.T) {
+	a := NewSlice[int]()
+	b := NewSlice[int]()
+	assert.Panics(t, func() {
+		_ = Atomically(func(tx *Txn) error {
+			TxnSlice(tx, b).Append(1)
+			return nil
+		}, a)
+	})
+}
+
+func TestAtomicallyOpposingOrderNoDeadlock(t *testing.T) {
+	a := NewMap[string, int]()
+	b := NewMap[string, int]()
+	a.Set("n", 1000)
+	b.Set("n", 1000)
+	move := func(from, to *Map[string, int]) {
+		_ = Atomically(func(tx *Txn) error {
+			f := TxnMap(tx, from)
+			g := TxnMap(tx, to)
+			x, _ := f.Get("n")
+			y, _ := g.Get("n")
+			f.Set("n", x-1)
+			g.Set("n", y+1)
+			return nil
+		}, from, to)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			move(a, b)
+		}()
+		go func() {
+			defer wg.Done()
+			move(b, a)
+		}()
+	}
+	wg.Wait()
+	x, _ := a.Get("n")
+	y, _ := b.Get("n")
+	assert.Equal(t, 1000, x)
+	assert.Equal(t, 1000, y)
+}
+
+func TestAtomicallyRollbackRestoresInPlaceChanges(t *testing.T) {
+	slice := NewSlice[int]()
+	for i := 1; i <= 4; i++ {
+		slice.Append(i)
+	}
+	queue := NewQueue()
+	queue.Enqueue("a")
+	queue.Enqueue("b")
+	stack := NewStack()
+	stack.Push(1)
+	stack.Push(2)
+
+	assert.Panics(t, func() {
+		Atomically(func(tx *Txn) error {
+			stx := TxnSlice(tx, slice)
+			stx.Set(0, 10)
+			stx.Remove(1)
+			stx.Insert(2, 20)
+			stx.Append(30)
+			stx.Remove(0)
+
+			qtx := TxnQueue(tx, queue)
+			qtx.Dequeue()
+			qtx.Enqueue("c")
+			qtx.Dequeue()
+			value, _ := qtx.Dequeue()
+			assert.Equal(t, "c", value)
+
+			sttx := TxnStack(tx, stack)
+			sttx.Pop()
+			sttx.Push(3)
+			sttx.Push(4)
+			sttx.Pop()
+			sttx.Pop()
+			sttx.Pop()
+			panic("boom")
+		}, slice, queue, stack)
+	})
+
+	assert.Equal(t, []int{1, 2, 3, 4}, slice.Values())
+	assert.Equal(t, []interface{}{"a", "b"}, queue.Values())
+	assert.Equal(t, []interface{}{2, 1}, stack.Values())
+}
+
+func TestAtomicallyDoesNotCopyCollections(t *testing.T) {
+	queue := NewQueue()
+	for i := 0; i < 10000; i++ {
+		queue.Enqueue(i)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		Atomically(func(tx *Txn) error {
+			value, _ := TxnQueue(tx, queue).Dequeue()
+			TxnQueue(tx, queue).Enqueue(value)
+			return nil
+		}, queue)
+	})
+	assert.Less(t, allocs, float64(50))
+	assert.Equal(t, 10000, queue.Len())
+}